@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// shortForms are the built-in one-letter commands, which win over prefixes
+// that would be ambiguous.
+var shortForms = map[string]string{
+	"a": "add",
+	"d": "due",
+	"e": "edit",
+	"l": "last",
+	"s": "show",
+	"t": "last", // today's pad
+}
+
+// expand resolves cmd to a subcommand. Aliases from `alias.<name>` settings
+// are expanded first, with their arguments put before the ones given; then
+// the short forms; then a scratch-<name> plugin on PATH is used as is;
+// failing that, a unique prefix of a subcommand stands for it, so
+// `scratch sh` is `scratch show`.
+func expand(cmd string, args []string) (string, []string) {
+	if _, ok := commands[cmd]; ok {
+		return cmd, args
+	}
+	if a := strings.Fields(conf("alias."+cmd, "")); len(a) > 0 {
+		cmd, args = a[0], append(a[1:], args...)
+		if _, ok := commands[cmd]; ok {
+			return cmd, args
+		}
+	}
+	if name, ok := shortForms[cmd]; ok {
+		return name, args
+	}
+	if isPlugin(cmd) {
+		return cmd, args
+	}
+	var matches []string
+	for name := range commands {
+		if name != "" && strings.HasPrefix(name, cmd) {
+			matches = append(matches, name)
+		}
+	}
+	if len(matches) > 1 {
+		sort.Strings(matches)
+		fmt.Fprintf(os.Stderr, tr("scratch: %q could be %s\n"), cmd, strings.Join(matches, ", "))
+		os.Exit(2)
+	}
+	if len(matches) == 1 {
+		return matches[0], args
+	}
+	return cmd, args
+}
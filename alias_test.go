@@ -0,0 +1,47 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExpand(t *testing.T) {
+	t.Setenv("SCRATCH_ALIAS_TODO", "add -context")
+	t.Setenv("SCRATCH_ALIAS_S", "stats -time")
+	for _, c := range []struct {
+		in   []string
+		want []string
+	}{
+		{[]string{"show", "x"}, []string{"show", "x"}},
+		{[]string{"todo", "buy milk"}, []string{"add", "-context", "buy milk"}},
+		{[]string{"t"}, []string{"last"}},
+		{[]string{"e", "-no-format"}, []string{"edit", "-no-format"}},
+		{[]string{"d"}, []string{"due"}},
+		{[]string{"s"}, []string{"stats", "-time"}}, // an alias beats a short form
+		{[]string{"sh"}, []string{"show"}},
+		{[]string{"tem", "preview"}, []string{"template", "preview"}},
+		{[]string{"nope"}, []string{"nope"}},
+	} {
+		cmd, args := expand(c.in[0], c.in[1:])
+		if got := append([]string{cmd}, args...); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("expand(%q) = %q, want %q", c.in, got, c.want)
+		}
+	}
+}
+
+func TestShortFormsAreCommands(t *testing.T) {
+	for short, name := range shortForms {
+		if _, ok := commands[name]; !ok {
+			t.Errorf("short form %s names unknown command %s", short, name)
+		}
+	}
+}
+
+func TestAmbiguousPrefix(t *testing.T) {
+	s := newSandbox(t)
+	_, errOut, code := s.run(nil, "ed")
+	if code != 2 || !strings.Contains(errOut, "could be edit, edit-config") {
+		t.Errorf("exit %d, stderr %q", code, errOut)
+	}
+}
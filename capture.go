@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// capture is a minimal line editor for when there is no other: it prints
+// the pad at p and appends what is typed, up to a lone "." or end of input.
+// The pad is replaced atomically, so an interrupted session leaves it whole.
+func capture(p string) {
+	b, err := readFile(p)
+	check(err)
+	os.Stdout.Write(b)
+	fmt.Fprintln(os.Stderr, tr("scratch: type lines to append; end with a line holding just . or Ctrl-D"))
+	var lines []string
+	in := bufio.NewScanner(os.Stdin)
+	in.Buffer(nil, 1<<20)
+	for in.Scan() && in.Text() != "." {
+		lines = append(lines, in.Text())
+	}
+	check(in.Err())
+	if len(lines) == 0 {
+		return
+	}
+	text := string(b)
+	if text != "" && !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+	text += strings.Join(lines, "\n") + "\n"
+	writeAtomic(p, []byte(text))
+}
+
+// writeAtomic replaces the file at p with b by renaming a temporary file
+// over it. Like moveAside, it falls back to writing in place on network and
+// sync filesystems that refuse renames. The temporary file is created with
+// the mode setting, so the umask applies as it does to new pads.
+func writeAtomic(p string, b []byte) {
+	b = lineEndings(p, b)
+	tmp := filepath.Join(filepath.Dir(realPath(p)), ".scratch-"+token())
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_EXCL, fileMode())
+	check(err)
+	removeOnExit(f.Name())
+	defer os.Remove(f.Name())
+	_, err = f.Write(b)
+	check(err)
+	check(f.Sync())
+	check(f.Close())
+	if os.Rename(f.Name(), realPath(p)) == nil {
+		return
+	}
+	out, err := os.OpenFile(realPath(p), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, fileMode())
+	check(err)
+	_, err = out.Write(b)
+	check(err)
+	check(out.Sync())
+	check(out.Close())
+}
@@ -0,0 +1,98 @@
+package main
+
+// Configuration lives in ~/.scratch/config as `key = value` lines.
+// Any key can be overridden with a SCRATCH_<KEY> environment variable.
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+var config = map[string]string{}
+
+func configPath() string {
+	return filepath.Join(home(), ".scratch", "config")
+}
+
+func loadConfig() {
+	f, err := os.Open(configPath())
+	if os.IsNotExist(err) {
+		return
+	}
+	check(err)
+	defer f.Close()
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		k, v, _ := strings.Cut(line, "=")
+		config[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+	check(s.Err())
+}
+
+func envName(key string) string {
+	r := strings.NewReplacer(".", "_", "-", "_")
+	return "SCRATCH_" + strings.ToUpper(r.Replace(key))
+}
+
+func conf(key, def string) string {
+	if v, ok := os.LookupEnv(envName(key)); ok {
+		return v
+	}
+	if v, ok := config[key]; ok {
+		return v
+	}
+	return def
+}
+
+// confPath reads a path setting, expanding a leading ~ and $VARIABLES. An
+// unset variable is an error rather than quietly expanding to nothing.
+func confPath(key, def string) string {
+	v := conf(key, "")
+	if v == "" {
+		return def
+	}
+	if v == "~" || strings.HasPrefix(v, "~/") {
+		v = home() + v[1:]
+	}
+	var missing string
+	v = os.Expand(v, func(name string) string {
+		val, ok := os.LookupEnv(name)
+		if !ok && missing == "" {
+			missing = name
+		}
+		return val
+	})
+	if missing != "" {
+		fmt.Fprintf(os.Stderr, tr("scratch: %s = %s: $%s is not set\n"), key, conf(key, ""), missing)
+		os.Exit(1)
+	}
+	return v
+}
+
+func confMode(key string, def os.FileMode) os.FileMode {
+	v := conf(key, "")
+	if v == "" {
+		return def
+	}
+	m, err := strconv.ParseUint(v, 8, 32)
+	check(err)
+	return os.FileMode(m)
+}
+
+func confBool(key string, def bool) bool {
+	v := conf(key, "")
+	if v == "" {
+		return def
+	}
+	b, err := strconv.ParseBool(v)
+	check(err)
+	return b
+}
@@ -0,0 +1,273 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A checker returns why a setting's value is invalid, or "".
+type checker func(v string) string
+
+func isBool(v string) string {
+	if _, err := strconv.ParseBool(v); err != nil {
+		return tr("want true or false")
+	}
+	return ""
+}
+
+func isMode(v string) string {
+	if _, err := strconv.ParseUint(v, 8, 32); err != nil {
+		return tr("want an octal mode like 0600")
+	}
+	return ""
+}
+
+func isDuration(v string) string {
+	if _, err := time.ParseDuration(v); err != nil {
+		return tr("want a duration like 30s or 24h")
+	}
+	return ""
+}
+
+func isInt(v string) string {
+	if _, err := strconv.Atoi(v); err != nil {
+		return tr("want a whole number")
+	}
+	return ""
+}
+
+func isFloat(v string) string {
+	if _, err := strconv.ParseFloat(v, 64); err != nil {
+		return tr("want a number")
+	}
+	return ""
+}
+
+func isRegexp(v string) string {
+	if _, err := regexp.Compile(v); err != nil {
+		return err.Error()
+	}
+	return ""
+}
+
+func isSize(v string) string {
+	if _, err := strconv.ParseInt(strings.TrimRight(strings.ToUpper(v), "KMG"), 10, 64); err != nil {
+		return tr("want a size like 500K or 10M")
+	}
+	return ""
+}
+
+func oneOf(choices ...string) checker {
+	return func(v string) string {
+		if !contains(choices, v) {
+			return fmt.Sprintf(tr("want one of %s"), strings.Join(choices, ", "))
+		}
+		return ""
+	}
+}
+
+func isTimeSections(v string) string {
+	for _, s := range strings.Split(v, ",") {
+		f := strings.Fields(s)
+		if len(f) < 2 {
+			return tr("want sections like Morning 05:00, Evening 17:00")
+		}
+		if _, err := time.Parse("15:04", f[len(f)-1]); err != nil {
+			return tr("want sections like Morning 05:00, Evening 17:00")
+		}
+	}
+	return ""
+}
+
+func isHolidays(v string) string {
+	for _, h := range strings.Split(v, ",") {
+		h = strings.TrimSpace(h)
+		_, errFull := time.Parse("2006-01-02", h)
+		_, errYearly := time.Parse("01-02", h)
+		if errFull != nil && errYearly != nil {
+			return tr("want dates like 2024-12-25 or, for every year, 12-25")
+		}
+	}
+	return ""
+}
+
+func isWrap(v string) string {
+	if n, err := strconv.Atoi(v); v != "unwrap" && (err != nil || n <= 0) {
+		return tr("want unwrap or a column to wrap at, like 80")
+	}
+	return ""
+}
+
+func anything(string) string { return "" }
+
+// settings lists every config key scratch reads, with a check of its value.
+var settings = map[string]checker{
+	"bell":                isBool,
+	"carry_max_age":       isDuration,
+	"command_timeout":     isDuration,
+	"context":             isBool,
+	"dir":                 anything,
+	"dedupe_window":       isDuration,
+	"dir_mode":            isMode,
+	"due_notify_interval": isDuration,
+	"editor_args":         anything,
+	"enrich":              anything,
+	"enrich_timeout":      isDuration,
+	"exit_summary":        isBool,
+	"formatter":           anything,
+	"holidays":            isHolidays,
+	"heading_style":       oneOf("plain", "setext", "box", "emoji"),
+	"hostname":            isBool,
+	"interstitial":        isBool,
+	"line_endings":        oneOf("keep", "lf", "crlf"),
+	"location_cmd":        anything,
+	"mode":                isMode,
+	"nag_hour":            isInt,
+	"normalize":           isBool,
+	"profile":             anything,
+	"quota":               isSize,
+	"quota_action":        oneOf("warn", "refuse"),
+	"rate_limit":          isInt,
+	"scan_secrets":        isBool,
+	"secret_entropy":      isFloat,
+	"session_cap":         isDuration,
+	"sidecar":             isBool,
+	"skip_weekends":       isBool,
+	"sections":            anything,
+	"summarize_cmd":       anything,
+	"summarize_prompt":    anything,
+	"time_sections":       isTimeSections,
+	"tls_cert":            anything,
+	"tls_key":             anything,
+	"token":               anything,
+	"transform":           anything,
+	"weather_location":    anything,
+	"wrap":                isWrap,
+}
+
+// families are the keys that take a name after a dot, like editor.last.
+var families = map[string]checker{
+	"alias":          anything,
+	"editor":         anything,
+	"enrich":         anything,
+	"pad":            anything,
+	"secret_pattern": isRegexp,
+	"timeout":        isDuration,
+	"tmux":           anything,
+	"token":          anything,
+	"viewer":         anything,
+}
+
+// checkConfig returns a problem for each config line that scratch can't
+// use, prefixed with its line number.
+func checkConfig() []string {
+	f, err := os.Open(configPath())
+	if os.IsNotExist(err) {
+		return nil
+	}
+	check(err)
+	defer f.Close()
+	var problems []string
+	s := bufio.NewScanner(f)
+	for n := 1; s.Scan(); n++ {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		k, v, ok := strings.Cut(line, "=")
+		k, v = strings.TrimSpace(k), strings.TrimSpace(v)
+		problem := ""
+		if !ok {
+			problem = tr("not a key = value line")
+		} else if c, known := lookupSetting(k); !known {
+			problem = fmt.Sprintf(tr("unknown setting %q"), k)
+			if near := nearestSetting(k); near != "" {
+				problem += fmt.Sprintf(tr("; did you mean %q?"), near)
+			}
+		} else if msg := c(v); msg != "" {
+			problem = fmt.Sprintf("%s = %s: %s", k, v, msg)
+		}
+		if problem != "" {
+			problems = append(problems, fmt.Sprintf("%s:%d: %s", configPath(), n, problem))
+		}
+	}
+	check(s.Err())
+	return problems
+}
+
+func lookupSetting(k string) (checker, bool) {
+	if c, ok := settings[k]; ok {
+		return c, true
+	}
+	family, name, ok := strings.Cut(k, ".")
+	if c, known := families[family]; ok && name != "" && known {
+		return c, true
+	}
+	return nil, false
+}
+
+// nearestSetting suggests the known key closest to a misspelt one.
+func nearestSetting(k string) string {
+	best, bestDist := "", 3
+	for s := range settings {
+		if d := distance(k, s); d < bestDist || d == bestDist && s < best {
+			best, bestDist = s, d
+		}
+	}
+	return best
+}
+
+// distance is the Levenshtein distance between a and b.
+func distance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+// editConfig opens the config in the editor and checks it once saved,
+// offering to edit again until it's clean.
+func editConfig() {
+	stateDir()
+	in := bufio.NewScanner(os.Stdin)
+	for {
+		e := append(editor("edit-config"), configPath())
+		cmd := exec.Command(e[0], e[1:]...)
+		cmd.Stdin = os.Stdin
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		err := runChild(cmd)
+		afterChild()
+		check(err)
+		problems := checkConfig()
+		if len(problems) == 0 {
+			return
+		}
+		for _, p := range problems {
+			fmt.Fprintln(os.Stderr, p)
+		}
+		fmt.Fprint(os.Stderr, tr("Edit again? [Y/n] "))
+		if !in.Scan() || strings.EqualFold(strings.TrimSpace(in.Text()), "n") {
+			os.Exit(1)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// countOpen prints how many tasks in the pad are still open and exits 0 if
+// there are any, 1 if not, so shell prompts and status lines can use it. The
+// count is cached against the pad's size and modification time.
+func countOpen() {
+	n := 0
+	if fi, err := os.Stat(padPath()); err == nil {
+		key := fmt.Sprintf("%s %d %d", padPath(), fi.ModTime().UnixNano(), fi.Size())
+		cache := filepath.Join(stateDir(), "count-open")
+		b, err := os.ReadFile(cache)
+		k, v, _ := strings.Cut(string(b), "\t")
+		if n, err = strconv.Atoi(strings.TrimSpace(v)); err != nil || k != key {
+			pad, err := readFile(padPath())
+			check(err)
+			n, _ = tasks(string(pad))
+			os.WriteFile(cache, []byte(fmt.Sprintf("%s\t%d\n", key, n)), fileMode())
+		}
+	}
+	fmt.Println(n)
+	if n == 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// doctor reports problems with the scratch files and exits nonzero if any
+// were found.
+func doctor() {
+	problems := 0
+	for _, p := range []string{padPath(), swpPath(), configPath(), inboxPath()} {
+		fi, err := os.Stat(p)
+		if os.IsNotExist(err) {
+			continue
+		}
+		check(err)
+		if fi.Mode().Perm()&0004 != 0 {
+			fmt.Printf(tr("%s is world-readable (%04o); run: chmod %04o %s\n"),
+				p, fi.Mode().Perm(), fileMode(), p)
+			problems++
+		}
+	}
+	for _, problem := range checkConfig() {
+		fmt.Println(problem)
+		problems++
+	}
+	for _, problem := range validate(padPath()) {
+		fmt.Printf("%s: %s\n", padPath(), problem)
+		problems++
+	}
+	for _, problem := range profileProblems() {
+		fmt.Println(problem)
+		problems++
+	}
+	if problems > 0 {
+		os.Exit(1)
+	}
+	fmt.Println(tr("No problems found."))
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+var dueRe = regexp.MustCompile(`\s*@due\((\d{4}-\d{2}-\d{2})\)`)
+
+// A dueItem is an open task with an @due(YYYY-MM-DD) date.
+type dueItem struct {
+	date time.Time
+	text string
+}
+
+// dueItems returns the open tasks in text that have a due date, soonest
+// first.
+func dueItems(text string) []dueItem {
+	var items []dueItem
+	for _, l := range strings.Split(text, "\n") {
+		m := taskRe.FindStringSubmatch(l)
+		if m == nil || m[1] != " " {
+			continue
+		}
+		d := dueRe.FindStringSubmatch(m[2])
+		if d == nil {
+			continue
+		}
+		date, err := time.ParseInLocation("2006-01-02", d[1], time.Local)
+		if err != nil {
+			continue
+		}
+		items = append(items, dueItem{date, strings.TrimSpace(dueRe.ReplaceAllString(m[2], ""))})
+	}
+	sort.SliceStable(items, func(i, j int) bool { return items[i].date.Before(items[j].date) })
+	return items
+}
+
+// due lists open tasks that are overdue or due within -days. With -notify it
+// sends a desktop notification for each one due today or overdue, at most
+// once per due_notify_interval, for running from a timer.
+func due(args []string) {
+	fs := flag.NewFlagSet("due", flag.ExitOnError)
+	days := fs.Int("days", 7, "how many days ahead to look")
+	notifyDue := fs.Bool("notify", false, "send desktop notifications for items due today or overdue")
+	truncateFlag(fs)
+	fs.Parse(args)
+	if fs.NArg() > 0 {
+		fmt.Fprintln(os.Stderr, "usage: scratch due [-days n] [-notify] [-no-truncate]")
+		os.Exit(2)
+	}
+	p := padPath()
+	if !exists(p) {
+		return
+	}
+	b := readNote(p)
+	y, m, d := time.Now().Date()
+	today := time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+	var sent map[string]time.Time
+	if *notifyDue {
+		sent = notified()
+	}
+	for _, item := range dueItems(string(b)) {
+		left := int(math.Round(item.date.Sub(today).Hours() / 24))
+		if left > *days {
+			break
+		}
+		when := fmt.Sprintf(tr("in %d days"), left)
+		switch {
+		case left < 0:
+			when = tr("overdue")
+		case left == 0:
+			when = tr("today")
+		}
+		fmt.Println(fit(fmt.Sprintf("%s  %-10s  %s", item.date.Format("2006-01-02"), when, item.text)))
+		if *notifyDue && left <= 0 && time.Since(sent[item.text]) >= notifyInterval() {
+			msg := tr("Overdue: %s")
+			if left == 0 {
+				msg = tr("Due today: %s")
+			}
+			notify("scratch", fmt.Sprintf(msg, item.text))
+			sent[item.text] = time.Now()
+		}
+	}
+	if *notifyDue {
+		saveNotified(sent)
+	}
+}
+
+func notifyInterval() time.Duration {
+	d, err := time.ParseDuration(conf("due_notify_interval", "24h"))
+	check(err)
+	return d
+}
+
+func notifiedPath() string {
+	return filepath.Join(stateDir(), "due-notified.json")
+}
+
+// notified returns when each due item was last notified about.
+func notified() map[string]time.Time {
+	sent := map[string]time.Time{}
+	b, err := os.ReadFile(notifiedPath())
+	if os.IsNotExist(err) {
+		return sent
+	}
+	check(err)
+	check(json.Unmarshal(b, &sent))
+	return sent
+}
+
+// saveNotified records when items were notified about, forgetting those
+// long past the interval.
+func saveNotified(sent map[string]time.Time) {
+	for text, t := range sent {
+		if time.Since(t) > 30*24*time.Hour {
+			delete(sent, text)
+		}
+	}
+	b, err := json.Marshal(sent)
+	check(err)
+	check(os.WriteFile(notifiedPath(), b, fileMode()))
+}
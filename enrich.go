@@ -0,0 +1,71 @@
+package main
+
+// Enrichers add a line of context, like the weather, under the title of a
+// fresh pad. Each one is a shell command or one of the built-in wttr.in
+// lookups, and is skipped if it fails or doesn't answer in time, so starting
+// a pad offline still works.
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// wttr maps the built-in enrichers to their wttr.in format strings.
+var wttr = map[string]string{
+	"weather": "%l: %c %t",
+	"sun":     "Sunrise %S, sunset %s",
+}
+
+// enrich inserts the output of the enrichers listed in `enrich` after the
+// first block of text, normally the title.
+func enrich(text string) string {
+	var lines []string
+	for _, name := range strings.Split(conf("enrich", ""), ",") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		if out := enricher(name); out != "" {
+			lines = append(lines, out)
+		}
+	}
+	if len(lines) == 0 {
+		return text
+	}
+	block := strings.Join(lines, "  \n") + "\n"
+	if i := strings.Index(text, "\n\n"); i >= 0 {
+		return text[:i+2] + block + text[i+1:]
+	}
+	return strings.TrimRight(text, "\n") + "\n\n" + block
+}
+
+// enricher runs one enricher, returning "" on failure.
+func enricher(name string) string {
+	if cmd := conf("enrich."+name, ""); cmd != "" {
+		out, err := helper("enrich", nil, nil, "sh", "-c", cmd)
+		if err != nil {
+			return ""
+		}
+		return strings.TrimSpace(string(out))
+	}
+	format, ok := wttr[name]
+	if !ok {
+		return ""
+	}
+	ctx, cancel := helperContext("enrich")
+	defer cancel()
+	u := "https://wttr.in/" + url.PathEscape(conf("weather_location", "")) + "?format=" + url.QueryEscape(format)
+	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+	check(err)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return ""
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if err != nil || resp.StatusCode != http.StatusOK {
+		return ""
+	}
+	return strings.TrimSpace(string(b))
+}
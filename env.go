@@ -0,0 +1,102 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// A setting is one line of `scratch where` or `scratch env` output.
+type setting struct{ key, value string }
+
+func locations() []setting {
+	tmpl := templateFile(time.Now())
+	if tmpl == "" {
+		tmpl = "built-in"
+	}
+	prof := profile()
+	if prof == "" {
+		prof = "default"
+	}
+	return []setting{
+		{"profile", prof},
+		{"dir", filepath.Dir(padPath())},
+		{"pad", padPath()},
+		{"config", configPath()},
+		{"state", stateDir()},
+		{"template", tmpl},
+	}
+}
+
+// where prints where scratch keeps its files.
+func where(args []string) {
+	printSettings("where", args, locations())
+}
+
+// env prints the fully resolved setup: locations, programs and settings.
+func env(args []string) {
+	s := append(locations(),
+		setting{"editor", strings.Join(editor("scratch"), " ")},
+		setting{"viewer", strings.Join(viewer("show"), " ")},
+		setting{"language", lang()},
+	)
+	keys := make([]string, 0, len(config))
+	for k := range config {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		s = append(s, setting{k, redact(k == "token" || strings.HasPrefix(k, "token."), conf(k, ""))})
+	}
+	var overrides []string
+	for _, kv := range os.Environ() {
+		if strings.HasPrefix(kv, "SCRATCH_") {
+			overrides = append(overrides, kv)
+		}
+	}
+	sort.Strings(overrides)
+	for _, kv := range overrides {
+		k, v, _ := strings.Cut(kv, "=")
+		s = append(s, setting{"$" + k, redact(k == "SCRATCH_TOKEN" || strings.HasPrefix(k, "SCRATCH_TOKEN_"), v)})
+	}
+	printSettings("env", args, s)
+}
+
+// redact hides the value of a secret, such as a bearer token, so env's
+// output can be pasted into a bug report.
+func redact(secret bool, v string) string {
+	if secret && v != "" {
+		return "(set)"
+	}
+	return v
+}
+
+func printSettings(name string, args []string, settings []setting) {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	asJSON := fs.Bool("json", false, "print as JSON")
+	truncateFlag(fs)
+	fs.Parse(args)
+	if *asJSON {
+		m := map[string]string{}
+		for _, s := range settings {
+			m[s.key] = s.value
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		check(enc.Encode(m))
+		return
+	}
+	width := 0
+	for _, s := range settings {
+		width = max(width, textWidth(s.key))
+	}
+	for _, s := range settings {
+		pad := strings.Repeat(" ", width-textWidth(s.key))
+		fmt.Println(fit(s.key + pad + "  " + s.value))
+	}
+}
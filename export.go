@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+var (
+	taskRe = regexp.MustCompile(`^\s*[-*+] \[([ xX])\] (.*)$`)
+	// Log entries are `- 15:04 text`, or `- 2006-01-02 15:04 text` when
+	// imported from another day.
+	logRe = regexp.MustCompile(`^- (?:(\d{4}-\d\d-\d\d) )?(\d\d:\d\d) (.*)$`)
+)
+
+// export writes the tasks or timestamped log entries of a note as CSV or
+// TSV, one row each, for analysis in a spreadsheet, or the log entries in
+// jrnl's plain text format, or the due tasks and events as an iCalendar
+// file.
+func export(args []string) {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	what := fs.String("what", "tasks", "what to export: tasks or log")
+	format := fs.String("format", "csv", "output format: csv, tsv, jrnl or ics")
+	fs.Parse(args)
+	if fs.NArg() > 1 || (*what != "tasks" && *what != "log") || !contains([]string{"csv", "tsv", "jrnl", "ics"}, *format) {
+		fmt.Fprintln(os.Stderr, "usage: scratch export [-what tasks|log] [-format csv|tsv|jrnl|ics] [file|-]")
+		os.Exit(2)
+	}
+	p := noteArg(fs.Args())
+	date := ""
+	if fi, err := os.Stat(p); err == nil && p != "-" {
+		date = fi.ModTime().Format("2006-01-02")
+	}
+	if *format == "ics" {
+		fmt.Print(calendar(string(readNote(p))))
+		return
+	}
+	lines := strings.Split(string(readNote(p)), "\n")
+	if *format == "jrnl" {
+		for i, l := range lines {
+			m := logRe.FindStringSubmatch(l)
+			if m == nil {
+				continue
+			}
+			day := m[1]
+			if day == "" {
+				day = date
+			}
+			fmt.Printf("[%s %s] %s\n", day, m[2], m[3])
+			// indented lines that follow are the entry's body, which may
+			// have blank lines in it
+			var body []string
+			for _, l := range lines[i+1:] {
+				if strings.TrimSpace(l) == "" {
+					body = append(body, "")
+					continue
+				}
+				if !strings.HasPrefix(l, "  ") {
+					break
+				}
+				body = append(body, strings.TrimPrefix(l, "  "))
+			}
+			text := strings.Trim(strings.Join(body, "\n"), "\n")
+			if text != "" {
+				fmt.Println(text)
+			}
+			fmt.Println()
+		}
+		return
+	}
+	w := csv.NewWriter(os.Stdout)
+	if *format == "tsv" {
+		w.Comma = '\t'
+	}
+	if *what == "tasks" {
+		w.Write([]string{"date", "section", "task", "done"})
+	} else {
+		w.Write([]string{"date", "time", "section", "entry"})
+	}
+	hs := sectionHeadings(lines)
+	sect := ""
+	for i, l := range lines {
+		for len(hs) > 0 && hs[0].line <= i {
+			sect, hs = hs[0].name, hs[1:]
+		}
+		if *what == "tasks" {
+			if m := taskRe.FindStringSubmatch(l); m != nil {
+				done := "false"
+				if m[1] != " " {
+					done = "true"
+				}
+				w.Write([]string{date, sect, m[2], done})
+			}
+		} else if m := logRe.FindStringSubmatch(l); m != nil {
+			day := m[1]
+			if day == "" {
+				day = date
+			}
+			w.Write([]string{day, m[2], sect, m[3]})
+		}
+	}
+	w.Flush()
+	check(w.Error())
+}
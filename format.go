@@ -0,0 +1,52 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+// format pipes the pad at p through the configured formatter command and
+// shows a diff of what it changed.
+func format(p string) {
+	formatter := conf("formatter", "")
+	if formatter == "" {
+		return
+	}
+	before, err := readFile(p)
+	check(err)
+	after, err := helper("formatter", bytes.NewReader(before), os.Stderr, "sh", "-c", formatter)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, tr("scratch: formatter failed, leaving note as is: %v\n"), err)
+		return
+	}
+	if bytes.Equal(before, after) {
+		return
+	}
+	showDiff(before, after)
+	writeAtomic(p, after)
+	journal("format", p, sum(before)+" -> "+sum(after))
+}
+
+func showDiff(before, after []byte) {
+	a := tempWith(before)
+	defer os.Remove(a)
+	b := tempWith(after)
+	defer os.Remove(b)
+	cmd := exec.Command("diff", "-u", "--label", "before", "--label", "after", a, b)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	// diff exits 1 when the files differ
+	cmd.Run()
+}
+
+func tempWith(b []byte) string {
+	f, err := os.CreateTemp("", "scratch-*.md")
+	check(err)
+	removeOnExit(f.Name())
+	defer f.Close()
+	_, err = f.Write(b)
+	check(err)
+	return f.Name()
+}
@@ -0,0 +1,3 @@
+module github.com/phrazzld/scratch
+
+go 1.22
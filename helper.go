@@ -0,0 +1,87 @@
+package main
+
+// Helper commands, like the formatter or an enricher, run with a timeout so
+// a hung script can't hold up scratch, and Ctrl-C stops the helper rather
+// than scratch. The editor, pager and `scratch run` aren't helpers.
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"time"
+)
+
+// helperTimeouts are the defaults for helpers expected to answer quickly,
+// or, like a local model summarizing, known to be slow.
+var helperTimeouts = map[string]string{
+	"enrich":    "3s",
+	"git":       "5s",
+	"link":      "5s",
+	"location":  "5s",
+	"notify":    "5s",
+	"summarize": "10m",
+	"tmux":      "2s",
+}
+
+// helperTimeout returns how long the named helper may run: its
+// timeout.<name> setting, else its default, else command_timeout.
+func helperTimeout(name string) time.Duration {
+	def := conf("command_timeout", "30s")
+	if d, ok := helperTimeouts[name]; ok {
+		def = d
+	}
+	if name == "enrich" {
+		def = conf("enrich_timeout", def)
+	}
+	d, err := time.ParseDuration(conf("timeout."+name, def))
+	check(err)
+	return d
+}
+
+// helperContext returns a context for the named helper that ends when its
+// time is up or on Ctrl-C.
+func helperContext(name string) (context.Context, context.CancelFunc) {
+	sigMu.Lock()
+	helpers++
+	sigMu.Unlock()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	ctx, cancel := context.WithTimeout(ctx, helperTimeout(name))
+	return ctx, func() {
+		cancel()
+		stop()
+		sigMu.Lock()
+		helpers--
+		sigMu.Unlock()
+	}
+}
+
+// helper runs argv as the named helper, feeding it stdin, and returns its
+// output. Its stderr goes to stderr, or nowhere if that is nil.
+func helper(name string, stdin io.Reader, stderr io.Writer, argv ...string) ([]byte, error) {
+	ctx, cancel := helperContext(name)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, argv[0], argv[1:]...)
+	cmd.Stdin = stdin
+	cmd.Stderr = stderr
+	// Don't wait on children of a shell still holding stdout open.
+	cmd.WaitDelay = 100 * time.Millisecond
+	out, err := cmd.Output()
+	return out, helperErr(ctx, name, err)
+}
+
+// helperErr explains a helper ended by its context.
+func helperErr(ctx context.Context, name string, err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return fmt.Errorf(tr("%s timed out after %v"), name, helperTimeout(name))
+	case errors.Is(ctx.Err(), context.Canceled):
+		return fmt.Errorf(tr("%s interrupted"), name)
+	}
+	return err
+}
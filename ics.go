@@ -0,0 +1,103 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var eventRe = regexp.MustCompile(`\s*@event\((\d{4}-\d{2}-\d{2})(?: (\d\d:\d\d))?\)`)
+
+// calendar renders the open @due tasks and the @event(YYYY-MM-DD [HH:MM])
+// lines of a note as an iCalendar feed. Due dates and untimed events are
+// all-day; timed events last an hour.
+func calendar(text string) string {
+	var b strings.Builder
+	b.WriteString("BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:-//scratch//EN\r\n")
+	stamp := time.Now().UTC().Format("20060102T150405Z")
+	event := func(start, end, summary string) {
+		sum := sha256.Sum256([]byte(start + summary))
+		b.WriteString("BEGIN:VEVENT\r\n")
+		b.WriteString("UID:" + hex.EncodeToString(sum[:8]) + "@scratch\r\n")
+		b.WriteString("DTSTAMP:" + stamp + "\r\n")
+		b.WriteString(start + "\r\n" + end + "\r\n")
+		b.WriteString(fold("SUMMARY:"+icsEscape(summary)) + "\r\n")
+		b.WriteString("END:VEVENT\r\n")
+	}
+	for _, item := range dueItems(text) {
+		event("DTSTART;VALUE=DATE:"+item.date.Format("20060102"),
+			"DTEND;VALUE=DATE:"+item.date.AddDate(0, 0, 1).Format("20060102"),
+			tr("Due: ")+item.text)
+	}
+	for _, l := range strings.Split(text, "\n") {
+		m := eventRe.FindStringSubmatch(l)
+		if m == nil {
+			continue
+		}
+		summary := eventRe.ReplaceAllString(l, "")
+		if t := taskRe.FindStringSubmatch(summary); t != nil {
+			if t[1] != " " {
+				continue
+			}
+			summary = t[2]
+		} else if lm := listRe.FindString(summary); lm != "" {
+			summary = summary[len(lm):]
+		}
+		summary = strings.TrimSpace(summary)
+		if m[2] == "" {
+			day, err := time.ParseInLocation("2006-01-02", m[1], time.Local)
+			if err != nil {
+				continue
+			}
+			event("DTSTART;VALUE=DATE:"+day.Format("20060102"),
+				"DTEND;VALUE=DATE:"+day.AddDate(0, 0, 1).Format("20060102"), summary)
+			continue
+		}
+		at, err := time.ParseInLocation("2006-01-02 15:04", m[1]+" "+m[2], time.Local)
+		if err != nil {
+			continue
+		}
+		event("DTSTART:"+at.UTC().Format("20060102T150405Z"),
+			"DTEND:"+at.Add(time.Hour).UTC().Format("20060102T150405Z"), summary)
+	}
+	b.WriteString("END:VCALENDAR\r\n")
+	return b.String()
+}
+
+// icsEscape escapes the characters iCalendar gives meaning to in text.
+func icsEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(s)
+}
+
+// fold splits a content line into the 75-octet lines iCalendar allows,
+// without breaking up a UTF-8 sequence.
+func fold(line string) string {
+	var b strings.Builder
+	n := 0
+	for _, r := range line {
+		size := len(string(r))
+		if n+size > 75 {
+			b.WriteString("\r\n ")
+			n = 1
+		}
+		b.WriteRune(r)
+		n += size
+	}
+	return b.String()
+}
+
+// serveCalendar serves the pad's calendar for subscribing to.
+func serveCalendar(w http.ResponseWriter, r *http.Request) {
+	p := padPath()
+	b, err := readFile(p)
+	if err != nil {
+		http.Error(w, "no scratchpad", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	fmt.Fprint(w, calendar(string(b)))
+}
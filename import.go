@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var jrnlRe = regexp.MustCompile(`^\[(\d{4}-\d\d-\d\d [^\]]+)\] ?(.*)$`)
+
+// jrnlTimes are the date formats jrnl writes, depending on its timeformat.
+var jrnlTimes = []string{
+	"2006-01-02 15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 03:04 PM",
+	"2006-01-02 03:04:05 PM",
+}
+
+// importCmd files the entries of a jrnl journal under the pad's Journal
+// section, keeping their dates.
+func importCmd(args []string) {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	from := fs.String("from", "jrnl", "format to import: jrnl")
+	fs.Parse(args)
+	if fs.NArg() != 1 || *from != "jrnl" {
+		fmt.Fprintln(os.Stderr, "usage: scratch import -from jrnl <file|->")
+		os.Exit(2)
+	}
+	checkQuota()
+	var f *os.File
+	if p := fs.Arg(0); p == "-" {
+		f = os.Stdin
+	} else {
+		var err error
+		f, err = os.Open(p)
+		check(err)
+		defer f.Close()
+	}
+	var e *entry
+	n := 0
+	flush := func() {
+		if e != nil {
+			e.Text = strings.TrimRight(e.Text, "\n ")
+			appendInbox(*e)
+			n++
+		}
+	}
+	s := bufio.NewScanner(f)
+	s.Buffer(nil, 1<<20)
+	for s.Scan() {
+		m := jrnlRe.FindStringSubmatch(s.Text())
+		if m == nil {
+			body(e, s.Text())
+			continue
+		}
+		var t time.Time
+		var err error
+		for _, layout := range jrnlTimes {
+			if t, err = time.Parse(layout, m[1]); err == nil {
+				break
+			}
+		}
+		if err != nil {
+			body(e, s.Text())
+			continue
+		}
+		flush()
+		e = &entry{Section: "Journal", Text: fmt.Sprintf("- %s %s", t.Format("2006-01-02 15:04"), m[2])}
+	}
+	check(s.Err())
+	flush()
+	fmt.Fprintf(os.Stderr, tr("Imported %d entries.\n"), n)
+}
+
+// body adds a line of a jrnl entry's body to e, indented under its title.
+// A blank line separates the body from the title, so that the two aren't
+// joined into one paragraph when the pad is reflowed.
+func body(e *entry, line string) {
+	if e == nil {
+		return
+	}
+	if !strings.Contains(e.Text, "\n") {
+		e.Text += "\n"
+	}
+	if strings.TrimSpace(line) == "" {
+		e.Text += "\n"
+	} else {
+		e.Text += "\n  " + line
+	}
+}
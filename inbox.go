@@ -0,0 +1,305 @@
+package main
+
+// Entries captured with `scratch add` are buffered in an inbox file instead
+// of being written to the pad directly, so an open editor session can't
+// overwrite them on save. The inbox is merged into the pad whenever it is
+// opened or closed.
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// An entry is one captured item. Entries with a Section are filed under
+// that `##` heading; the rest go at the end of the pad.
+type entry struct {
+	Section string `json:"section,omitempty"`
+	Text    string `json:"text"`
+}
+
+// stateDirErr is why ~/.scratch couldn't be created, if it was refused.
+var stateDirErr error
+
+func stateDir() string {
+	d := filepath.Join(home(), ".scratch")
+	// on a read-only home, writes fail later and are put down to this
+	if err := os.MkdirAll(d, dirMode()); err != nil {
+		if !readOnly(err) {
+			check(err)
+		}
+		stateDirErr = err
+	}
+	return d
+}
+
+func dirMode() os.FileMode {
+	return confMode("dir_mode", 0700)
+}
+
+func inboxPath() string {
+	if p := profile(); p != "" {
+		return filepath.Join(stateDir(), "inbox-"+p+".jsonl")
+	}
+	return filepath.Join(stateDir(), "inbox.jsonl")
+}
+
+func add(args []string) {
+	fs := flag.NewFlagSet("add", flag.ExitOnError)
+	withContext := fs.Bool("context", confBool("context", false), "note the git repo, branch and directory")
+	withHost := fs.Bool("host", confBool("hostname", false), "note the machine and, with location_cmd, the location")
+	fs.Parse(args)
+	args = fs.Args()
+	text := strings.Join(args, " ")
+	if len(args) == 0 {
+		b, err := io.ReadAll(os.Stdin)
+		check(err)
+		text = strings.TrimSpace(string(b))
+	}
+	if text == "" {
+		fmt.Fprintln(os.Stderr, "usage: scratch add <text>")
+		os.Exit(2)
+	}
+	checkQuota()
+	var meta []string
+	if *withContext {
+		if c := gitContext(); c != "" {
+			meta = append(meta, c)
+		}
+	}
+	if *withHost {
+		meta = append(meta, hostContext()...)
+	}
+	if len(meta) > 0 {
+		text += " _(" + strings.Join(meta, "; ") + ")_"
+	}
+	if duplicate(text) {
+		fmt.Fprintln(os.Stderr, tr("scratch: skipping a repeat of the last entry"))
+		return
+	}
+	appendInbox(logEntry(text))
+}
+
+// duplicate reports whether text was also the last thing added, within
+// dedupe_window, as happens when a keybinding fires twice. If not, text
+// becomes the last entry.
+func duplicate(text string) bool {
+	window, err := time.ParseDuration(conf("dedupe_window", "10s"))
+	check(err)
+	p := filepath.Join(stateDir(), "last-add")
+	b, _ := os.ReadFile(p)
+	when, last, _ := strings.Cut(string(b), "\t")
+	t, err := time.Parse(time.RFC3339Nano, when)
+	dup := err == nil && last == text && time.Since(t) < window
+	if !dup {
+		check(os.WriteFile(p, []byte(time.Now().Format(time.RFC3339Nano)+"\t"+text), fileMode()))
+	}
+	return dup
+}
+
+// logEntry makes a timestamped list item for the end of the pad, or for the
+// current time of day's section if time_sections is set.
+func logEntry(text string) entry {
+	now := time.Now()
+	return entry{Section: timeSection(now), Text: fmt.Sprintf("- %s %s", now.Format("15:04"), text)}
+}
+
+// timeSection returns the section that time_sections, e.g. `Morning 05:00,
+// Afternoon 12:00, Evening 17:00`, assigns to t. Before the first start time
+// it is still the last section of the day before.
+func timeSection(t time.Time) string {
+	type slot struct{ start, name string }
+	var slots []slot
+	for _, s := range strings.Split(conf("time_sections", ""), ",") {
+		f := strings.Fields(s)
+		if len(f) < 2 {
+			continue
+		}
+		start := f[len(f)-1]
+		_, err := time.Parse("15:04", start)
+		check(err)
+		slots = append(slots, slot{start, strings.Join(f[:len(f)-1], " ")})
+	}
+	if len(slots) == 0 {
+		return ""
+	}
+	sort.Slice(slots, func(i, j int) bool { return slots[i].start < slots[j].start })
+	name := slots[len(slots)-1].name
+	for _, s := range slots {
+		if s.start <= t.Format("15:04") {
+			name = s.name
+		}
+	}
+	return name
+}
+
+// gitContext describes where in a git checkout scratch was run, or returns
+// "" outside of one.
+func gitContext() string {
+	top, err := git("rev-parse", "--show-toplevel")
+	if err != nil {
+		return ""
+	}
+	branch, err := git("rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		return ""
+	}
+	wd, err := os.Getwd()
+	check(err)
+	if rel, err := filepath.Rel(home(), wd); err == nil && !strings.HasPrefix(rel, "..") {
+		wd = filepath.Join("~", rel)
+	}
+	return fmt.Sprintf("%s@%s in %s", filepath.Base(top), branch, wd)
+}
+
+// hostContext names the machine scratch runs on and, if location_cmd is
+// set, where it is.
+func hostContext() []string {
+	var meta []string
+	if h, err := os.Hostname(); err == nil {
+		meta = append(meta, "on "+h)
+	}
+	if cmd := conf("location_cmd", ""); cmd != "" {
+		out, err := helper("location", nil, nil, "sh", "-c", cmd)
+		if loc := strings.TrimSpace(string(out)); err == nil && loc != "" {
+			meta = append(meta, "near "+loc)
+		}
+	}
+	return meta
+}
+
+func appendInbox(e entry) {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	check(enc.Encode(e))
+	f, err := os.OpenFile(inboxPath(), os.O_WRONLY|os.O_CREATE|os.O_APPEND, fileMode())
+	check(err)
+	defer f.Close()
+	_, err = f.Write(b.Bytes())
+	check(err)
+	recordSidecar(e)
+}
+
+// mergeInbox moves any buffered entries into the pad at p. The inbox is
+// renamed aside first so entries added during the merge aren't lost; a
+// leftover from an interrupted merge is picked up first.
+func mergeInbox(p string) {
+	in := inboxPath()
+	merging := in + ".merging"
+	if exists(merging) {
+		mergeFile(p, merging)
+	}
+	if exists(in) {
+		moveAside(in, merging)
+		mergeFile(p, merging)
+	}
+}
+
+// pending returns the entries waiting in the inbox, including any left from
+// an interrupted merge.
+func pending() []entry {
+	var entries []entry
+	for _, p := range []string{inboxPath() + ".merging", inboxPath()} {
+		e, _ := readEntries(p)
+		entries = append(entries, e...)
+	}
+	return entries
+}
+
+// readEntries reads the inbox file at p. A line that isn't valid JSON, such
+// as one cut short by a crash or a full disk, is kept as the text of an
+// entry rather than lost; damaged counts them.
+func readEntries(p string) (entries []entry, damaged int) {
+	b, err := os.ReadFile(p)
+	if os.IsNotExist(err) {
+		return nil, 0
+	}
+	check(err)
+	for _, l := range strings.Split(string(b), "\n") {
+		if strings.TrimSpace(l) == "" {
+			continue
+		}
+		var e entry
+		if json.Unmarshal([]byte(l), &e) != nil {
+			e, damaged = entry{Text: l}, damaged+1
+		}
+		entries = append(entries, e)
+	}
+	return entries, damaged
+}
+
+// withPending returns the pad's text with the pending entries put in, for
+// commands that only read it. They leave the pad alone: an editor may have
+// it open and would overwrite the entries when it saves.
+func withPending(text string) string {
+	for _, e := range pending() {
+		text = insert(text, e)
+	}
+	return text
+}
+
+// moveAside renames src to dst. Some network and sync filesystems refuse
+// renames, so fall back to copying src and truncating it.
+func moveAside(src, dst string) {
+	if os.Rename(src, dst) == nil {
+		return
+	}
+	b, err := os.ReadFile(src)
+	check(err)
+	check(os.WriteFile(dst, b, fileMode()))
+	check(os.Truncate(src, 0))
+}
+
+func mergeFile(p, src string) {
+	entries, damaged := readEntries(src)
+	if damaged > 0 {
+		fmt.Fprintf(os.Stderr, tr("scratch: %d damaged inbox entries added as they are; check the end of the scratchpad\n"), damaged)
+	}
+	pad, err := readFile(p)
+	check(err)
+	text := string(pad)
+	for _, e := range entries {
+		text = insert(text, e)
+	}
+	writeAtomic(p, []byte(text))
+	check(os.Remove(src))
+	journal("merge", p, fmt.Sprintf("%s -> %s (%d entries)", sum(pad), sum([]byte(text)), len(entries)))
+}
+
+// insert adds e to the end of its section in text, creating the section
+// at the end of the note if it doesn't exist yet.
+func insert(text string, e entry) string {
+	if text != "" && !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+	body := strings.TrimRight(e.Text, "\n")
+	if e.Section == "" {
+		return text + body + "\n"
+	}
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	var at int
+	first, end, ok := section(lines, e.Section)
+	if ok {
+		at = end
+		for at > first && strings.TrimSpace(lines[at-1]) == "" {
+			at--
+		}
+	} else {
+		lines = append(lines, "")
+		lines = append(lines, strings.Split(sectionHeading(e.Section), "\n")...)
+		lines = append(lines, "")
+		at = len(lines)
+	}
+	out := append([]string{}, lines[:at]...)
+	out = append(out, strings.Split(body, "\n")...)
+	out = append(out, lines[at:]...)
+	return strings.Join(out, "\n") + "\n"
+}
@@ -0,0 +1,105 @@
+package main
+
+// Everything scratch does to a note is recorded in ~/.scratch/actions.log,
+// one tab-separated line per action, so it's possible to tell afterwards
+// what happened to a pad.
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+func journalPath() string {
+	return filepath.Join(stateDir(), "actions.log")
+}
+
+func journal(action, file, detail string) {
+	f, err := os.OpenFile(journalPath(), os.O_WRONLY|os.O_CREATE|os.O_APPEND, fileMode())
+	check(err)
+	defer f.Close()
+	_, err = fmt.Fprintf(f, "%s\t%s\t%s\t%s\n",
+		time.Now().Format(time.RFC3339), action, filepath.Base(file), detail)
+	check(err)
+}
+
+// hash returns the SHA-256 of the file at p, or "-" if it doesn't exist.
+func hash(p string) string {
+	b, err := os.ReadFile(p)
+	if os.IsNotExist(err) {
+		return "-"
+	}
+	check(err)
+	return sum(b)
+}
+
+func sum(b []byte) string {
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:])
+}
+
+// history prints the journal entries for a day, today by default.
+func history(args []string) {
+	fs := flag.NewFlagSet("history", flag.ExitOnError)
+	truncateFlag(fs)
+	fs.Parse(args)
+	args = fs.Args()
+	day := time.Now().Format("2006-01-02")
+	if len(args) > 0 {
+		_, err := time.Parse("2006-01-02", args[0])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "usage: scratch history [-no-truncate] [YYYY-MM-DD]")
+			os.Exit(2)
+		}
+		day = args[0]
+	}
+	f, err := os.Open(journalPath())
+	if os.IsNotExist(err) {
+		return
+	}
+	check(err)
+	defer f.Close()
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		if strings.HasPrefix(s.Text(), day) {
+			fmt.Println(fit(strings.ReplaceAll(s.Text(), "\t", "  ")))
+		}
+	}
+	check(s.Err())
+}
+
+// started returns the hash the pad at p had once scratch finished setting it
+// up, from its last reset, or "" if the journal doesn't say.
+func started(p string) string {
+	f, err := os.Open(journalPath())
+	if os.IsNotExist(err) {
+		return ""
+	}
+	check(err)
+	defer f.Close()
+	h := ""
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		fields := strings.SplitN(s.Text(), "\t", 4)
+		if len(fields) < 4 || fields[2] != filepath.Base(p) {
+			continue
+		}
+		switch fields[1] {
+		case "reset":
+			h = ""
+			fallthrough
+		case "plan":
+			if _, after, ok := strings.Cut(fields[3], " -> "); ok {
+				h = after
+			}
+		}
+	}
+	check(s.Err())
+	return h
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// jumpLine is the line the editor opens the pad at, if not the first.
+var jumpLine int
+
+// jump opens the pad with the editor at one of its sections: the first whose
+// name starts with the argument, else one picked with fzf or from a numbered
+// menu.
+func jump(args []string) {
+	args = editFlags("jump", args)
+	checkQuota()
+	p := requirePad()
+	mergeInbox(p)
+	b, err := readFile(p)
+	check(err)
+	hs := sectionHeadings(strings.Split(string(b), "\n"))
+	if len(hs) == 0 {
+		fmt.Fprintln(os.Stderr, tr("scratch: the scratchpad has no sections"))
+		os.Exit(1)
+	}
+	var h *heading
+	if want := strings.ToLower(strings.Join(args, " ")); want != "" {
+		for i := range hs {
+			if strings.HasPrefix(strings.ToLower(hs[i].name), want) {
+				h = &hs[i]
+				break
+			}
+		}
+		if h == nil {
+			fmt.Fprintf(os.Stderr, tr("scratch: no section starting %q\n"), want)
+			os.Exit(1)
+		}
+	} else {
+		h = pickHeading(hs)
+	}
+	jumpLine = h.line + 1
+	edit("jump", p)
+}
+
+// pickHeading asks which section to open, with fzf if it's installed.
+func pickHeading(hs []heading) *heading {
+	var names bytes.Buffer
+	for i, h := range hs {
+		fmt.Fprintf(&names, "%d %s\n", i+1, h.name)
+	}
+	if _, err := exec.LookPath("fzf"); err == nil {
+		cmd := exec.Command("fzf", "--with-nth=2..", "--no-sort")
+		var out bytes.Buffer
+		cmd.Stdin = &names
+		cmd.Stdout = &out
+		cmd.Stderr = os.Stderr
+		err := runChild(cmd)
+		afterChild()
+		if err != nil {
+			os.Exit(1)
+		}
+		n, _ := strconv.Atoi(strings.Fields(out.String())[0])
+		return &hs[n-1]
+	}
+	fmt.Fprint(os.Stderr, names.String())
+	in := bufio.NewScanner(os.Stdin)
+	for {
+		fmt.Fprint(os.Stderr, tr("Section number? "))
+		if !in.Scan() {
+			os.Exit(1)
+		}
+		if n, err := strconv.Atoi(strings.TrimSpace(in.Text())); err == nil && n >= 1 && n <= len(hs) {
+			return &hs[n-1]
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var titleRe = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+
+// link files a markdown link to url under the Links section, using the
+// page title when it can be fetched.
+func link(args []string) {
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, "usage: scratch link <url>")
+		os.Exit(2)
+	}
+	url := args[0]
+	title := strings.NewReplacer("[", `\[`, "]", `\]`).Replace(pageTitle(url))
+	appendInbox(entry{
+		Section: "Links",
+		Text:    fmt.Sprintf("- [%s](%s) — %s", title, url, time.Now().Format("15:04")),
+	})
+}
+
+// pageTitle fetches url and returns its <title>, or url itself if the page
+// can't be reached in time or has no title.
+func pageTitle(url string) string {
+	ctx, cancel := helperContext("link")
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return url
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return url
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return url
+	}
+	m := titleRe.FindSubmatch(b)
+	if m == nil {
+		return url
+	}
+	title := strings.Join(strings.Fields(html.UnescapeString(string(m[1]))), " ")
+	if title == "" {
+		return url
+	}
+	return title
+}
@@ -0,0 +1,176 @@
+package main
+
+// User-facing messages are looked up in a catalog keyed by their English
+// text, using the language from LC_ALL, LC_MESSAGES or LANG. Usage lines,
+// HTTP responses and internal errors stay in English.
+
+import (
+	"os"
+	"strings"
+)
+
+var catalog = map[string]map[string]string{
+	"es": {
+		"%s contents:\n\n": "Contenido de %s:\n\n",
+		"%s is world-readable (%04o); run: chmod %04o %s\n": "%s es legible por todos (%04o); ejecuta: chmod %04o %s\n",
+		"No problems found.":                                                    "No se encontraron problemas.",
+		"unknown section %q":                                                    "sección desconocida %q",
+		"missing section %q":                                                    "falta la sección %q",
+		"Nothing in your scratchpad yet today.":                                 "Hoy todavía no has escrito nada en tu bloc de notas.",
+		"Sharing scratchpad at %s%s\n":                                          "Compartiendo el bloc de notas en %s%s\n",
+		"Accepting entries at %s/append\n":                                      "Aceptando entradas en %s/append\n",
+		"Serving for %v\n":                                                      "Sirviendo durante %v\n",
+		"scratch: set token in the config to accept appends":                    "scratch: define token en la configuración para aceptar entradas",
+		"scratch: formatter failed, leaving note as is: %v\n":                   "scratch: el formateador falló, la nota queda como estaba: %v\n",
+		"scratch: no scratchpad yet; run scratch to start one":                  "scratch: todavía no hay bloc de notas; ejecuta scratch para empezar uno",
+		"scratch: unknown command %q\n":                                         "scratch: orden desconocida %q\n",
+		"scratch: unknown day %q\n":                                             "scratch: día desconocido %q\n",
+		"scratch: no timer running":                                             "scratch: no hay ningún temporizador en marcha",
+		"%s:%d: possible %s %s\n":                                               "%s:%d: posible %s %s\n",
+		"scratch: %d possible secrets in %s; run scratch scan-secrets -mask\n":  "scratch: %d posibles secretos en %s; ejecuta scratch scan-secrets -mask\n",
+		"scratch: editor exited abnormally: %v\n":                               "scratch: el editor terminó de forma anómala: %v\n",
+		"scratch: found swap file %s (modified %s)\n":                           "scratch: se encontró el archivo de intercambio %s (modificado %s)\n",
+		"[r]ecover, [d]iff, discard [x] or [q]uit? ":                            "¿[r] recuperar, [d] ver diferencias, [x] descartar o [q] salir? ",
+		"scratch: scratch files use %s, over the %s quota; see scratch stats\n": "scratch: los archivos de scratch ocupan %s, por encima de la cuota de %s; consulta scratch stats\n",
+		"scratch: refusing to write: scratch files use %s, over the %s quota; see scratch stats\n": "scratch: no se escribirá nada: los archivos de scratch ocupan %s, por encima de la cuota de %s; consulta scratch stats\n",
+		"scratch: not in a git repository":                                        "scratch: no estás en un repositorio git",
+		"scratch: carrying plans from a scratchpad last written %s:\n":            "scratch: se traerán los planes de un bloc de notas escrito por última vez el %s:\n",
+		"  %d lines, %d open and %d done tasks\n":                                 "  %d líneas, %d tareas pendientes y %d hechas\n",
+		"Carry them into the new scratchpad? [Y/n] ":                              "¿Llevarlos al nuevo bloc de notas? [Y/n] ",
+		"scratch: %s not found; using the built-in editor\n":                      "scratch: no se encontró %s; se usará el editor integrado\n",
+		"scratch: type lines to append; end with a line holding just . or Ctrl-D": "scratch: escribe las líneas que quieras añadir; termina con una línea que solo tenga . o con Ctrl-D",
+		"scratch: set summarize_cmd in the config, e.g. summarize_cmd = llm":      "scratch: define summarize_cmd en la configuración, p. ej. summarize_cmd = llm",
+		"scratch: summarize_cmd failed: %v\n":                                     "scratch: summarize_cmd falló: %v\n",
+		"scratch: %q could be %s\n":                                               "scratch: %q puede ser %s\n",
+		"scratch: transform failed, starting from the template: %v\n":             "scratch: la transformación falló, se parte de la plantilla: %v\n",
+		"in %d days":    "en %d días",
+		"overdue":       "vencida",
+		"today":         "hoy",
+		"Overdue: %s":   "Vencida: %s",
+		"Due today: %s": "Vence hoy: %s",
+		"profile %q uses the same scratchpad, %s":         "el perfil %q usa el mismo bloc de notas, %s",
+		"scratchpad was started under profile %q":         "el bloc de notas se empezó con el perfil %q",
+		"want true or false":                              "se espera true o false",
+		"want an octal mode like 0600":                    "se espera un modo octal como 0600",
+		"want a duration like 30s or 24h":                 "se espera una duración como 30s o 24h",
+		"want a whole number":                             "se espera un número entero",
+		"want a number":                                   "se espera un número",
+		"want a size like 500K or 10M":                    "se espera un tamaño como 500K o 10M",
+		"want one of %s":                                  "se espera uno de %s",
+		"want sections like Morning 05:00, Evening 17:00": "se esperan secciones como Morning 05:00, Evening 17:00",
+		"not a key = value line":                          "no es una línea clave = valor",
+		"unknown setting %q":                              "ajuste desconocido %q",
+		"; did you mean %q?":                              "; ¿querías decir %q?",
+		"Edit again? [Y/n] ":                              "¿Editar de nuevo? [Y/n] ",
+		"Imported %d entries.\n":                          "Se importaron %d entradas.\n",
+		"want unwrap or a column to wrap at, like 80":     "se espera unwrap o una columna en la que ajustar, como 80",
+		"%s timed out after %v":                           "%s superó el tiempo límite de %v",
+		"%s interrupted":                                  "%s interrumpido",
+		"scratch: keeping the current scratchpad on a day off; pass -force to start a fresh one": "scratch: se mantiene el bloc de notas actual en un día libre; usa -force para empezar uno nuevo",
+		"want dates like 2024-12-25 or, for every year, 12-25":                                   "se esperan fechas como 2024-12-25 o, para todos los años, 12-25",
+		"  (%d capped at %v)":                                   "  (%d limitadas a %v)",
+		"scratch: %s = %s: $%s is not set\n":                    "scratch: %s = %s: $%s no está definida\n",
+		"Web page at %s%s\n":                                    "Página web en %s%s\n",
+		"Calendar at %s%s\n":                                    "Calendario en %s%s\n",
+		"Due: ":                                                 "Vence: ",
+		"scratch: the scratchpad has no sections":               "scratch: el bloc de notas no tiene secciones",
+		"scratch: no section starting %q\n":                     "scratch: ninguna sección empieza por %q\n",
+		"Section number? ":                                      "¿Número de sección? ",
+		"scratch: +%d/-%d words, %d new tasks, %d done, %v%s\n": "scratch: +%d/-%d palabras, %d tareas nuevas, %d hechas, %v%s\n",
+		"scratch: can't write to %s; it is read-only\n":         "scratch: no se puede escribir en %s; es de solo lectura\n",
+		"scratch: skipping a repeat of the last entry":          "scratch: se omite una repetición de la última entrada",
+		"scratch: %d damaged inbox entries added as they are; check the end of the scratchpad\n": "scratch: %d entradas dañadas de la bandeja añadidas tal cual; revisa el final del bloc de notas\n",
+		"scratch: can't create %s for the scratchpad: %v\n":                                      "scratch: no se puede crear %s para el bloc de notas: %v\n",
+	},
+	"de": {
+		"%s contents:\n\n": "Inhalt von %s:\n\n",
+		"%s is world-readable (%04o); run: chmod %04o %s\n": "%s ist für alle lesbar (%04o); ausführen: chmod %04o %s\n",
+		"No problems found.":                                                    "Keine Probleme gefunden.",
+		"unknown section %q":                                                    "unbekannter Abschnitt %q",
+		"missing section %q":                                                    "Abschnitt %q fehlt",
+		"Nothing in your scratchpad yet today.":                                 "Heute steht noch nichts in deinem Notizblock.",
+		"Sharing scratchpad at %s%s\n":                                          "Notizblock wird geteilt unter %s%s\n",
+		"Accepting entries at %s/append\n":                                      "Einträge werden angenommen unter %s/append\n",
+		"Serving for %v\n":                                                      "Bereitstellung für %v\n",
+		"scratch: set token in the config to accept appends":                    "scratch: setze token in der Konfiguration, um Einträge anzunehmen",
+		"scratch: formatter failed, leaving note as is: %v\n":                   "scratch: Formatierer fehlgeschlagen, Notiz bleibt unverändert: %v\n",
+		"scratch: no scratchpad yet; run scratch to start one":                  "scratch: noch kein Notizblock; starte einen mit scratch",
+		"scratch: unknown command %q\n":                                         "scratch: unbekannter Befehl %q\n",
+		"scratch: unknown day %q\n":                                             "scratch: unbekannter Tag %q\n",
+		"scratch: no timer running":                                             "scratch: kein Timer läuft",
+		"%s:%d: possible %s %s\n":                                               "%s:%d: möglicherweise %s %s\n",
+		"scratch: %d possible secrets in %s; run scratch scan-secrets -mask\n":  "scratch: %d mögliche Geheimnisse in %s; führe scratch scan-secrets -mask aus\n",
+		"scratch: editor exited abnormally: %v\n":                               "scratch: Editor wurde unerwartet beendet: %v\n",
+		"scratch: found swap file %s (modified %s)\n":                           "scratch: Auslagerungsdatei %s gefunden (geändert %s)\n",
+		"[r]ecover, [d]iff, discard [x] or [q]uit? ":                            "[r] wiederherstellen, [d] Unterschiede, [x] verwerfen oder [q] beenden? ",
+		"scratch: scratch files use %s, over the %s quota; see scratch stats\n": "scratch: scratch-Dateien belegen %s, mehr als das Kontingent von %s; siehe scratch stats\n",
+		"scratch: refusing to write: scratch files use %s, over the %s quota; see scratch stats\n": "scratch: Schreiben verweigert: scratch-Dateien belegen %s, mehr als das Kontingent von %s; siehe scratch stats\n",
+		"scratch: not in a git repository":                                        "scratch: nicht in einem Git-Repository",
+		"scratch: carrying plans from a scratchpad last written %s:\n":            "scratch: Pläne aus einem zuletzt am %s geschriebenen Notizblock werden übernommen:\n",
+		"  %d lines, %d open and %d done tasks\n":                                 "  %d Zeilen, %d offene und %d erledigte Aufgaben\n",
+		"Carry them into the new scratchpad? [Y/n] ":                              "In den neuen Notizblock übernehmen? [Y/n] ",
+		"scratch: %s not found; using the built-in editor\n":                      "scratch: %s nicht gefunden; der eingebaute Editor wird verwendet\n",
+		"scratch: type lines to append; end with a line holding just . or Ctrl-D": "scratch: Zeilen zum Anhängen eingeben; mit einer Zeile nur aus . oder mit Strg-D beenden",
+		"scratch: set summarize_cmd in the config, e.g. summarize_cmd = llm":      "scratch: setze summarize_cmd in der Konfiguration, z. B. summarize_cmd = llm",
+		"scratch: summarize_cmd failed: %v\n":                                     "scratch: summarize_cmd fehlgeschlagen: %v\n",
+		"scratch: %q could be %s\n":                                               "scratch: %q könnte %s sein\n",
+		"scratch: transform failed, starting from the template: %v\n":             "scratch: Transformation fehlgeschlagen, die Vorlage wird verwendet: %v\n",
+		"in %d days":    "in %d Tagen",
+		"overdue":       "überfällig",
+		"today":         "heute",
+		"Overdue: %s":   "Überfällig: %s",
+		"Due today: %s": "Heute fällig: %s",
+		"profile %q uses the same scratchpad, %s":         "Profil %q verwendet denselben Notizblock, %s",
+		"scratchpad was started under profile %q":         "Notizblock wurde mit dem Profil %q begonnen",
+		"want true or false":                              "erwartet true oder false",
+		"want an octal mode like 0600":                    "erwartet einen oktalen Modus wie 0600",
+		"want a duration like 30s or 24h":                 "erwartet eine Dauer wie 30s oder 24h",
+		"want a whole number":                             "erwartet eine ganze Zahl",
+		"want a number":                                   "erwartet eine Zahl",
+		"want a size like 500K or 10M":                    "erwartet eine Größe wie 500K oder 10M",
+		"want one of %s":                                  "erwartet eines von %s",
+		"want sections like Morning 05:00, Evening 17:00": "erwartet Abschnitte wie Morning 05:00, Evening 17:00",
+		"not a key = value line":                          "keine Zeile der Form Schlüssel = Wert",
+		"unknown setting %q":                              "unbekannte Einstellung %q",
+		"; did you mean %q?":                              "; meintest du %q?",
+		"Edit again? [Y/n] ":                              "Erneut bearbeiten? [Y/n] ",
+		"Imported %d entries.\n":                          "%d Einträge importiert.\n",
+		"want unwrap or a column to wrap at, like 80":     "erwartet unwrap oder eine Spalte für den Umbruch, z. B. 80",
+		"%s timed out after %v":                           "%s hat die Zeitgrenze von %v überschritten",
+		"%s interrupted":                                  "%s unterbrochen",
+		"scratch: keeping the current scratchpad on a day off; pass -force to start a fresh one": "scratch: der aktuelle Notizblock bleibt an einem freien Tag erhalten; mit -force einen neuen beginnen",
+		"want dates like 2024-12-25 or, for every year, 12-25":                                   "erwartet Daten wie 2024-12-25 oder, für jedes Jahr, 12-25",
+		"  (%d capped at %v)":                                   "  (%d auf %v begrenzt)",
+		"scratch: %s = %s: $%s is not set\n":                    "scratch: %s = %s: $%s ist nicht gesetzt\n",
+		"Web page at %s%s\n":                                    "Webseite unter %s%s\n",
+		"Calendar at %s%s\n":                                    "Kalender unter %s%s\n",
+		"Due: ":                                                 "Fällig: ",
+		"scratch: the scratchpad has no sections":               "scratch: der Notizblock hat keine Abschnitte",
+		"scratch: no section starting %q\n":                     "scratch: kein Abschnitt beginnt mit %q\n",
+		"Section number? ":                                      "Abschnittsnummer? ",
+		"scratch: +%d/-%d words, %d new tasks, %d done, %v%s\n": "scratch: +%d/-%d Wörter, %d neue Aufgaben, %d erledigt, %v%s\n",
+		"scratch: can't write to %s; it is read-only\n":         "scratch: %s kann nicht geschrieben werden; schreibgeschützt\n",
+		"scratch: skipping a repeat of the last entry":          "scratch: Wiederholung des letzten Eintrags übersprungen",
+		"scratch: %d damaged inbox entries added as they are; check the end of the scratchpad\n": "scratch: %d beschädigte Einträge unverändert übernommen; prüfe das Ende des Notizblocks\n",
+		"scratch: can't create %s for the scratchpad: %v\n":                                      "scratch: %s für den Notizblock kann nicht angelegt werden: %v\n",
+	},
+}
+
+// lang returns the two-letter language code of the user's locale.
+func lang() string {
+	for _, v := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if l := os.Getenv(v); l != "" {
+			return strings.ToLower(l[:min(2, len(l))])
+		}
+	}
+	return "en"
+}
+
+// tr translates an English message, returning it unchanged if there is no
+// translation.
+func tr(s string) string {
+	if t, ok := catalog[lang()][s]; ok {
+		return t
+	}
+	return s
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// nag sends a desktop notification if nothing has been written to the pad
+// today. It's meant to be run from a timer; before nag_hour it does nothing.
+func nag() {
+	hour, err := strconv.Atoi(conf("nag_hour", "0"))
+	check(err)
+	now := time.Now()
+	if now.Hour() < hour || writtenToday(padPath(), now) {
+		return
+	}
+	notify("scratch", tr("Nothing in your scratchpad yet today."))
+}
+
+func writtenToday(p string, now time.Time) bool {
+	fi, err := os.Stat(p)
+	if os.IsNotExist(err) {
+		return false
+	}
+	check(err)
+	y, m, d := now.Date()
+	if fi.ModTime().Before(time.Date(y, m, d, 0, 0, 0, 0, now.Location())) {
+		return false
+	}
+	if h := started(p); h != "" {
+		return hash(p) != h
+	}
+	b, err := readFile(p)
+	check(err)
+	return strings.TrimSpace(string(b)) != strings.TrimSpace(padTemplate())
+}
+
+// notify shows a desktop notification, falling back to stdout when no
+// notifier is available.
+func notify(title, msg string) {
+	argv := []string{"notify-send", title, msg}
+	if runtime.GOOS == "darwin" {
+		argv = []string{"osascript", "-e",
+			fmt.Sprintf("display notification %q with title %q", msg, title)}
+	}
+	if _, err := helper("notify", nil, nil, argv...); err != nil {
+		fmt.Printf("%s: %s\n", title, msg)
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+)
+
+// Notes synced from Windows can have CRLF line endings. They are read with
+// plain newlines, so that headings and sections parse, and written back in
+// the style set by line_endings: "keep" the file's own, or always "lf" or
+// "crlf".
+
+// readFile reads the note at p with its line endings made plain newlines.
+func readFile(p string) ([]byte, error) {
+	b, err := os.ReadFile(p)
+	return bytes.ReplaceAll(b, []byte("\r\n"), []byte("\n")), err
+}
+
+// lineEndings converts b to the line endings the note at p should be
+// written with.
+func lineEndings(p string, b []byte) []byte {
+	b = bytes.ReplaceAll(b, []byte("\r\n"), []byte("\n"))
+	switch style := conf("line_endings", "keep"); style {
+	case "lf":
+		return b
+	case "crlf":
+	case "keep":
+		old, err := os.ReadFile(p)
+		if err != nil || !bytes.Contains(old, []byte("\r\n")) {
+			return b
+		}
+	default:
+		check(fmt.Errorf("unknown line_endings %q; use keep, lf or crlf", style))
+	}
+	return bytes.ReplaceAll(b, []byte("\n"), []byte("\r\n"))
+}
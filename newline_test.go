@@ -0,0 +1,79 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func fixture(t *testing.T, name string) string {
+	b, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+// lf reports whether every line of text ends in a bare newline.
+func lf(text string) bool { return !strings.Contains(text, "\r") }
+
+// crlf reports whether every line of text ends in CRLF.
+func crlf(text string) bool {
+	return strings.Count(text, "\n") == strings.Count(text, "\r\n")
+}
+
+func TestCRLFPlanIsCarried(t *testing.T) {
+	s := newSandbox(t)
+	s.write("scratchpad.md", fixture(t, "crlf.md"))
+	s.ok()
+	pad := s.read("scratchpad.md")
+	if !strings.Contains(pad, "## Plan\r\n\r\n- [ ] carry me\r\n") {
+		t.Errorf("plan not carried:\n%q", pad)
+	}
+	if !crlf(pad) {
+		t.Errorf("line endings mixed:\n%q", pad)
+	}
+}
+
+func TestCRLFKeptOnMerge(t *testing.T) {
+	s := newSandbox(t)
+	s.write("scratchpad.md", fixture(t, "crlf.md"))
+	s.ok("add", "from linux")
+	s.ok("last", "-no-format")
+	pad := s.read("scratchpad.md")
+	if !strings.Contains(pad, "from linux\r\n") || !crlf(pad) {
+		t.Errorf("entry not merged with CRLF:\n%q", pad)
+	}
+}
+
+func TestCRLFSections(t *testing.T) {
+	s := newSandbox(t)
+	s.write("scratchpad.md", fixture(t, "crlf.md"))
+	s.write(".scratch/config", "time_sections = Log 00:00\n")
+	s.ok("add", "filed")
+	out := s.ok("export", "-what", "log")
+	if !strings.Contains(out, ",Log,synced from windows") || !strings.Contains(out, ",Log,filed") {
+		t.Errorf("log entries not found in their section:\n%s", out)
+	}
+}
+
+func TestLineEndingsSetting(t *testing.T) {
+	for _, c := range []struct {
+		setting string
+		start   string
+		ok      func(string) bool
+	}{
+		{"lf", fixture(t, "crlf.md"), lf},
+		{"crlf", "# Today\n", crlf},
+		{"keep", "# Today\n", lf},
+	} {
+		s := newSandbox(t)
+		s.write("scratchpad.md", c.start)
+		s.write(".scratch/config", "line_endings = "+c.setting+"\n")
+		s.ok("add", "merged")
+		s.ok("last", "-no-format")
+		if pad := s.read("scratchpad.md"); !c.ok(pad) {
+			t.Errorf("line_endings = %s wrote\n%q", c.setting, pad)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// tomorrow returns what was written under the pad's `## Tomorrow` heading,
+// or "" if there is no such section.
+func tomorrow(p string) string {
+	b, err := readFile(p)
+	if err != nil {
+		return ""
+	}
+	lines := strings.Split(string(b), "\n")
+	body, end, ok := section(lines, "Tomorrow")
+	if !ok {
+		return ""
+	}
+	return strings.Trim(strings.Join(lines[body:end], "\n"), "\n")
+}
+
+// confirmPlan asks before carrying plans from a pad that was last written
+// more than carry_max_age ago, showing what would be carried. Only a "no"
+// drops them; without a terminal to ask on they are carried, as the old pad
+// is about to be replaced.
+func confirmPlan(p, plan string) bool {
+	fi, err := os.Stat(p)
+	if err != nil || plan == "" {
+		return true
+	}
+	age, err := time.ParseDuration(conf("carry_max_age", "24h"))
+	check(err)
+	if time.Since(fi.ModTime()) <= age {
+		return true
+	}
+	fmt.Fprintf(os.Stderr, tr("scratch: carrying plans from a scratchpad last written %s:\n"),
+		fi.ModTime().Format("2006-01-02 15:04"))
+	lines := strings.Split(plan, "\n")
+	for _, l := range lines {
+		if strings.HasPrefix(l, "#") {
+			fmt.Fprintln(os.Stderr, "  "+bold(l))
+		}
+	}
+	open, done := tasks(plan)
+	fmt.Fprintf(os.Stderr, tr("  %d lines, %d open and %d done tasks\n"), len(lines), open, done)
+	if fi, err := os.Stdin.Stat(); err != nil || fi.Mode()&os.ModeCharDevice == 0 {
+		return true
+	}
+	fmt.Fprint(os.Stderr, tr("Carry them into the new scratchpad? [Y/n] "))
+	in := bufio.NewScanner(os.Stdin)
+	if !in.Scan() {
+		fmt.Fprintln(os.Stderr)
+		return true
+	}
+	answer := strings.ToLower(strings.TrimSpace(in.Text()))
+	if answer == "n" || answer == "no" {
+		journal("plan", p, "declined: "+sum([]byte(plan)))
+		return false
+	}
+	return true
+}
+
+// tasks counts the open and done checkboxes in text.
+func tasks(text string) (open, done int) {
+	for _, l := range strings.Split(text, "\n") {
+		if m := taskRe.FindStringSubmatch(l); m != nil && m[1] == " " {
+			open++
+		} else if m != nil {
+			done++
+		}
+	}
+	return open, done
+}
+
+// bold highlights s when stderr is a terminal.
+func bold(s string) string {
+	if fi, err := os.Stderr.Stat(); err != nil || fi.Mode()&os.ModeCharDevice == 0 {
+		return s
+	}
+	return "\x1b[1;36m" + s + "\x1b[0m"
+}
+
+// seed returns the text a fresh pad starts with: the template, with any
+// enrichers' output, or the old pad at p piped through the transform command
+// if one is set. The old pad's `## Tomorrow` section is left out of a
+// transformed one, as carryPlan moves it into the plan.
+func seed(p string) string {
+	command := conf("transform", "")
+	if command == "" || !exists(p) {
+		return enrich(padTemplate())
+	}
+	old, err := readFile(p)
+	check(err)
+	out, err := helper("transform", bytes.NewReader(old), os.Stderr, "sh", "-c", command)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, tr("scratch: transform failed, starting from the template: %v\n"), err)
+		return enrich(padTemplate())
+	}
+	return dropSection(string(out), "Tomorrow")
+}
+
+// dropSection removes the named section, heading and all, from text.
+func dropSection(text, name string) string {
+	lines := strings.Split(text, "\n")
+	for _, h := range sectionHeadings(lines) {
+		if h.name != name {
+			continue
+		}
+		_, end, _ := section(lines, name)
+		if end == len(lines) {
+			return strings.TrimRight(strings.Join(lines[:h.line], "\n"), "\n") + "\n"
+		}
+		return strings.Join(append(lines[:h.line:h.line], lines[end:]...), "\n")
+	}
+	return text
+}
+
+// carryPlan files the old pad's plans for tomorrow under `## Plan` in the
+// fresh pad at p.
+func carryPlan(p, plan string) {
+	if strings.TrimSpace(plan) == "" {
+		return
+	}
+	b, err := readFile(p)
+	check(err)
+	text := insert(string(b), entry{Section: "Plan", Text: plan})
+	if confBool("normalize", true) {
+		text = normalize(text)
+	}
+	writeAtomic(p, []byte(text))
+	journal("plan", p, fmt.Sprintf("%s -> %s", sum(b), sum([]byte(text))))
+}
+
+// normalize tidies whitespace so it doesn't build up as plans are carried
+// from pad to pad: trailing spaces go, except a markdown hard break's two,
+// runs of blank lines are cut to two, and the text ends in one newline.
+func normalize(text string) string {
+	var out []string
+	blank := 0
+	for _, l := range strings.Split(text, "\n") {
+		t := strings.TrimRight(l, " \t")
+		if strings.HasSuffix(l, "  ") && strings.TrimSpace(t) != "" {
+			t += "  "
+		}
+		if t == "" {
+			if blank++; blank > 2 {
+				continue
+			}
+		} else {
+			blank = 0
+		}
+		out = append(out, t)
+	}
+	return strings.TrimRight(strings.Join(out, "\n"), "\n") + "\n"
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+)
+
+// isPlugin reports whether a scratch-<name> executable is on PATH.
+func isPlugin(name string) bool {
+	_, err := exec.LookPath("scratch-" + name)
+	return name != "" && err == nil
+}
+
+// plugin runs the scratch-<name> executable for a command scratch doesn't
+// have, as git does, and exits with its status. It is told where things are
+// in SCRATCH_DIR, SCRATCH_TODAY (the scratchpad) and SCRATCH_PROFILE.
+func plugin(name string, args []string) {
+	cmd := exec.Command("scratch-"+name, args...)
+	cmd.Env = append(os.Environ(),
+		"SCRATCH_DIR="+padDir(),
+		"SCRATCH_TODAY="+padPath(),
+		"SCRATCH_PROFILE="+profile(),
+	)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	err := runChild(cmd)
+	afterChild()
+	var exit *exec.ExitError
+	if errors.As(err, &exit) {
+		os.Exit(exit.ExitCode())
+	}
+	check(err)
+	os.Exit(0)
+}
@@ -0,0 +1,121 @@
+package main
+
+// Profiles keep separate pads for separate contexts, such as an on-call
+// notebook alongside the personal one. The profile comes from the `profile`
+// setting, usually set with $SCRATCH_PROFILE, or from the name of the tmux
+// session scratch runs in if it is mapped with `tmux.<session>`.
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+var activeProfile *string
+
+// profile returns the active profile, or "" for the default pad.
+func profile() string {
+	if activeProfile != nil {
+		return *activeProfile
+	}
+	p := conf("profile", "")
+	if p == "" && os.Getenv("TMUX") != "" {
+		out, err := helper("tmux", nil, nil, "tmux", "display-message", "-p", "#S")
+		if err == nil {
+			p = conf("tmux."+strings.TrimSpace(string(out)), "")
+		}
+	}
+	activeProfile = &p
+	return p
+}
+
+// profilePad returns the pad for profile p: the `pad.<profile>` setting, or
+// ~/scratchpad-<profile>.md.
+func profilePad(p string) string {
+	if pad := confPath("pad."+p, ""); pad != "" {
+		return pad
+	}
+	return filepath.Join(padDir(), "scratchpad-"+p+".md")
+}
+
+// profiles lists every profile named in the config, plus the active one.
+func profiles() []string {
+	seen := map[string]bool{}
+	if p := profile(); p != "" {
+		seen[p] = true
+	}
+	for k, v := range config {
+		if name, ok := strings.CutPrefix(k, "pad."); ok {
+			seen[name] = true
+		} else if strings.HasPrefix(k, "tmux.") && v != "" {
+			seen[v] = true
+		}
+	}
+	names := []string{}
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// profileProblems reports other profiles whose pad is the same file as the
+// active profile's, and a pad last started under a different profile.
+func profileProblems() []string {
+	var problems []string
+	mine := realPath(padPath())
+	active := profile()
+	for _, name := range append([]string{""}, profiles()...) {
+		if name == active {
+			continue
+		}
+		pad := filepath.Join(padDir(), "scratchpad.md")
+		label := "default"
+		if name != "" {
+			pad, label = profilePad(name), name
+		}
+		if realPath(pad) == mine {
+			problems = append(problems, fmt.Sprintf(tr("profile %q uses the same scratchpad, %s"), label, mine))
+		}
+	}
+	if owner, ok := padProfile(padPath()); ok && owner != active {
+		problems = append(problems, fmt.Sprintf(tr("scratchpad was started under profile %q"), owner))
+	}
+	return problems
+}
+
+const profileMark = "<!-- scratch profile: %s -->"
+
+// markProfile records the active profile at the top of a new pad's text,
+// replacing any mark a transformed old pad brings with it.
+func markProfile(text string) string {
+	if first, rest, _ := strings.Cut(text, "\n"); strings.HasPrefix(first, strings.TrimSuffix(profileMark, "%s -->")) {
+		text = rest
+	}
+	if p := profile(); p != "" {
+		return fmt.Sprintf(profileMark, p) + "\n" + text
+	}
+	return text
+}
+
+// padProfile returns the profile recorded in the pad at p, if any.
+func padProfile(p string) (string, bool) {
+	b, err := readFile(p)
+	if err != nil {
+		return "", false
+	}
+	first, _, _ := strings.Cut(string(b), "\n")
+	var name string
+	if _, err := fmt.Sscanf(first, "<!-- scratch profile: %s -->", &name); err != nil {
+		return "", false
+	}
+	return name, true
+}
+
+func warnProfiles() {
+	for _, problem := range profileProblems() {
+		fmt.Fprintf(os.Stderr, "scratch: %s\n", problem)
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+// exitReadOnly is the status scratch exits with when it can't write because
+// the filesystem or directory is read-only: EX_CANTCREAT from sysexits.h.
+const exitReadOnly = 73
+
+// readOnly reports whether err is from a write that was refused: any write
+// on a read-only filesystem, or one denied permission. Being denied a read
+// isn't, so an unreadable file fails like any other error.
+func readOnly(err error) bool {
+	if inRefusedStateDir(err) {
+		return true
+	}
+	if errors.Is(err, syscall.EROFS) {
+		return true
+	}
+	if !errors.Is(err, fs.ErrPermission) {
+		return false
+	}
+	var linkErr *os.LinkError
+	if errors.As(err, &linkErr) {
+		return true
+	}
+	var pathErr *fs.PathError
+	if !errors.As(err, &pathErr) {
+		return false
+	}
+	switch pathErr.Op {
+	case "mkdir", "createtemp", "write", "truncate", "chmod", "remove", "unlink", "rename", "sync":
+		return true
+	case "open":
+		// open is used both ways: it was a write if the file can be read,
+		// or doesn't exist yet and was being created
+		f, err := os.Open(pathErr.Path)
+		if err == nil {
+			f.Close()
+			return true
+		}
+		return errors.Is(err, fs.ErrNotExist)
+	}
+	return false
+}
+
+// inRefusedStateDir reports whether err is from a file missing because
+// ~/.scratch couldn't be created in the first place.
+func inRefusedStateDir(err error) bool {
+	var pathErr *fs.PathError
+	if stateDirErr == nil || !errors.Is(err, fs.ErrNotExist) || !errors.As(err, &pathErr) {
+		return false
+	}
+	d := filepath.Join(home(), ".scratch")
+	return strings.HasPrefix(pathErr.Path, d+string(filepath.Separator))
+}
+
+// exitIfReadOnly turns a panic from a failed write to a read-only location
+// into a message and exitReadOnly. Other panics carry on.
+func exitIfReadOnly() {
+	r := recover()
+	if r == nil {
+		return
+	}
+	err, ok := r.(error)
+	if !ok || !readOnly(err) {
+		panic(r)
+	}
+	path := err.Error()
+	var pathErr *fs.PathError
+	var linkErr *os.LinkError
+	if inRefusedStateDir(err) {
+		path = filepath.Join(home(), ".scratch")
+	} else if errors.As(err, &pathErr) {
+		path = pathErr.Path
+	} else if errors.As(err, &linkErr) {
+		path = linkErr.New
+	}
+	fmt.Fprintf(os.Stderr, tr("scratch: can't write to %s; it is read-only\n"), path)
+	os.Exit(exitReadOnly)
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+func TestReadOnly(t *testing.T) {
+	dir := t.TempDir()
+	readable := filepath.Join(dir, "readable")
+	check(os.WriteFile(readable, nil, 0600))
+	unreadable := filepath.Join(dir, "unreadable")
+	check(os.WriteFile(unreadable, nil, 0))
+	missing := filepath.Join(dir, "missing")
+	for _, c := range []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"read-only filesystem", &fs.PathError{Op: "open", Path: readable, Err: syscall.EROFS}, true},
+		{"write denied", &fs.PathError{Op: "open", Path: readable, Err: syscall.EACCES}, true},
+		{"create denied", &fs.PathError{Op: "open", Path: missing, Err: syscall.EACCES}, true},
+		{"mkdir denied", &fs.PathError{Op: "mkdir", Path: missing, Err: syscall.EACCES}, true},
+		{"rename denied", &os.LinkError{Op: "rename", Old: readable, New: missing, Err: syscall.EACCES}, true},
+		{"read denied", &fs.PathError{Op: "read", Path: readable, Err: syscall.EACCES}, false},
+		{"stat denied", &fs.PathError{Op: "stat", Path: readable, Err: syscall.EACCES}, false},
+		{"not found", &fs.PathError{Op: "open", Path: missing, Err: syscall.ENOENT}, false},
+	} {
+		if got := readOnly(c.err); got != c.want {
+			t.Errorf("%s: readOnly = %v, want %v", c.name, got, c.want)
+		}
+	}
+	t.Setenv("HOME", dir)
+	inState := &fs.PathError{Op: "open", Path: filepath.Join(dir, ".scratch", "inbox.jsonl"), Err: syscall.ENOENT}
+	if readOnly(inState) {
+		t.Error("a file missing from ~/.scratch counts as a refused write")
+	}
+	// ~/.scratch couldn't be made, so every file in it is missing
+	stateDirErr = &fs.PathError{Op: "mkdir", Path: filepath.Join(dir, ".scratch"), Err: syscall.EACCES}
+	defer func() { stateDirErr = nil }()
+	if !readOnly(inState) {
+		t.Error("a file missing from a ~/.scratch that couldn't be made isn't a refused write")
+	}
+	if readOnly(&fs.PathError{Op: "open", Path: missing, Err: syscall.ENOENT}) {
+		t.Error("a file missing elsewhere counts as a refused write")
+	}
+	if os.Geteuid() != 0 {
+		err := &fs.PathError{Op: "open", Path: unreadable, Err: syscall.EACCES}
+		if readOnly(err) {
+			t.Error("opening an unreadable file counts as a refused write")
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// recoverPad handles an editor that exited abnormally. If vim left a swap
+// file behind, offer to recover it, diff it against the pad or discard it.
+func recoverPad(p string, editErr error) {
+	fmt.Fprintf(os.Stderr, tr("scratch: editor exited abnormally: %v\n"), editErr)
+	swp := swpPath()
+	fi, err := os.Stat(swp)
+	if err != nil {
+		code := 1
+		var exit *exec.ExitError
+		if errors.As(editErr, &exit) && exit.ExitCode() > 0 {
+			code = exit.ExitCode()
+		}
+		os.Exit(code)
+	}
+	fmt.Fprintf(os.Stderr, tr("scratch: found swap file %s (modified %s)\n"),
+		swp, fi.ModTime().Format("2006-01-02 15:04"))
+	in := bufio.NewScanner(os.Stdin)
+	for {
+		fmt.Fprint(os.Stderr, tr("[r]ecover, [d]iff, discard [x] or [q]uit? "))
+		if !in.Scan() {
+			os.Exit(1)
+		}
+		switch strings.TrimSpace(in.Text()) {
+		case "r":
+			old := hash(p)
+			writeAtomic(p, recovered(p))
+			journal("recover", p, old+" -> "+hash(p))
+			discardSwap(swp)
+			return
+		case "d":
+			pad, err := os.ReadFile(p)
+			check(err)
+			showDiff(pad, recovered(p))
+		case "x":
+			discardSwap(swp)
+			return
+		case "q":
+			os.Exit(1)
+		}
+	}
+}
+
+// recovered returns the pad as recovered from vim's swap file.
+func recovered(p string) []byte {
+	tmp, err := os.MkdirTemp("", "scratch-recover-")
+	check(err)
+	removeOnExit(tmp)
+	defer os.RemoveAll(tmp)
+	out := filepath.Join(tmp, "recovered.md")
+	cmd := exec.Command("vim", "-Nu", "NONE", "-i", "NONE", "-es", "-r", p, "-c", "w! "+out, "-c", "qa!")
+	cmd.Stderr = os.Stderr
+	check(cmd.Run())
+	b, err := os.ReadFile(out)
+	check(err)
+	return b
+}
+
+func discardSwap(swp string) {
+	journal("delete", swp, hash(swp))
+	check(os.Remove(swp))
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ref logs a link to the current HEAD commit, or to a pull request when
+// given its number, in the repository scratch is run from.
+func ref(args []string) {
+	if len(args) > 1 {
+		fmt.Fprintln(os.Stderr, "usage: scratch ref [pull-request-number]")
+		os.Exit(2)
+	}
+	top, err := git("rev-parse", "--show-toplevel")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, tr("scratch: not in a git repository"))
+		os.Exit(1)
+	}
+	repo := filepath.Base(top)
+	web := forgeURL()
+
+	if len(args) == 1 {
+		pr := strings.TrimPrefix(args[0], "#")
+		text := fmt.Sprintf("%s #%s", repo, pr)
+		if web != "" {
+			text = fmt.Sprintf("%s [#%s](%s)", repo, pr, web+pullPath(web)+pr)
+		}
+		appendInbox(logEntry(text))
+		return
+	}
+
+	sha, err := git("rev-parse", "HEAD")
+	check(err)
+	subject, err := git("log", "-1", "--format=%s")
+	check(err)
+	short := sha[:min(7, len(sha))]
+	text := fmt.Sprintf("%s@%s %s", repo, short, subject)
+	if web != "" {
+		text = fmt.Sprintf("%s@[%s](%s) %s", repo, short, web+commitPath(web)+sha, subject)
+	}
+	appendInbox(logEntry(text))
+}
+
+func git(args ...string) (string, error) {
+	out, err := helper("git", nil, nil, append([]string{"git"}, args...)...)
+	return strings.TrimSpace(string(out)), err
+}
+
+// forgeURL turns the origin remote into the repository's web address, or
+// returns "" if there is no usable origin.
+func forgeURL() string {
+	remote, err := git("remote", "get-url", "origin")
+	if err != nil || remote == "" {
+		return ""
+	}
+	remote = strings.TrimSuffix(remote, ".git")
+	// scp-like syntax: git@host:owner/repo
+	if !strings.Contains(remote, "://") {
+		if at := strings.Index(remote, "@"); at >= 0 {
+			remote = remote[at+1:]
+		}
+		host, path, ok := strings.Cut(remote, ":")
+		if !ok {
+			return ""
+		}
+		return "https://" + host + "/" + strings.TrimPrefix(path, "/")
+	}
+	u, err := url.Parse(remote)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	return "https://" + u.Hostname() + u.Path
+}
+
+func commitPath(web string) string {
+	switch {
+	case strings.Contains(web, "gitlab"):
+		return "/-/commit/"
+	case strings.Contains(web, "bitbucket"):
+		return "/commits/"
+	}
+	return "/commit/"
+}
+
+func pullPath(web string) string {
+	switch {
+	case strings.Contains(web, "gitlab"):
+		return "/-/merge_requests/"
+	case strings.Contains(web, "bitbucket"):
+		return "/pull-requests/"
+	case strings.Contains(web, "gitea") || strings.Contains(web, "codeberg"):
+		return "/pulls/"
+	}
+	return "/pull/"
+}
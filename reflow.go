@@ -0,0 +1,127 @@
+package main
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	listRe  = regexp.MustCompile(`^(\s*)([-*+]|\d+[.)])( \[[ xX]\])? `)
+	blockRe = regexp.MustCompile(`^\s*(#|\||>|<|[\x{2500}-\x{257F}]|` + "```" + `|={3,}\s*$|-{3,}\s*$|\*{3,}\s*$)`)
+)
+
+// wrapWidth returns the column the wrap setting asks paragraphs to be
+// wrapped at, 0 to unwrap them, or -1 to leave them alone.
+func wrapWidth() int {
+	w := conf("wrap", "")
+	switch w {
+	case "":
+		return -1
+	case "unwrap":
+		return 0
+	}
+	n, err := strconv.Atoi(w)
+	check(err)
+	return n
+}
+
+// reflowPad rewraps the paragraphs and list items of the pad at p so it is
+// stored the same way whichever editor wrote it.
+func reflowPad(p string) {
+	width := wrapWidth()
+	if width < 0 {
+		return
+	}
+	before, err := readFile(p)
+	check(err)
+	after := reflow(string(before), width)
+	if after == string(before) {
+		return
+	}
+	writeAtomic(p, []byte(after))
+	journal("reflow", p, sum(before)+" -> "+sum([]byte(after)))
+}
+
+// reflow joins the lines of each paragraph and list item, then wraps them at
+// width columns unless width is 0. Code blocks, tables, headings, quotes,
+// box drawing and HTML are left alone, like indented code, as are markdown
+// hard breaks. Indented paragraphs after a blank line stay in their item.
+func reflow(text string, width int) string {
+	lines := strings.Split(text, "\n")
+	var out, para []string
+	first, rest, cont := "", "", ""
+	// the indent of the list item being read, kept across blank lines
+	item := ""
+	hard := false
+	flush := func() {
+		if len(para) == 0 {
+			return
+		}
+		words := strings.Fields(strings.Join(para, " "))
+		para = nil
+		line := first
+		for _, w := range words {
+			if line != first && line != rest && width > 0 && textWidth(line)+1+textWidth(w) > width {
+				out = append(out, line)
+				line = rest
+			}
+			if line == first || line == rest {
+				line += w
+			} else {
+				line += " " + w
+			}
+		}
+		if hard {
+			line += "  "
+		}
+		out = append(out, line)
+		first, rest, cont, hard = "", "", "", false
+	}
+	fenced := false
+	for i, l := range lines {
+		if strings.TrimSpace(l) != "" && !strings.HasPrefix(l, item) {
+			item = ""
+		}
+		switch {
+		case strings.HasPrefix(strings.TrimSpace(l), "```"):
+			flush()
+			fenced = !fenced
+			out = append(out, l)
+		case fenced, strings.TrimSpace(l) == "":
+			flush()
+			out = append(out, l)
+		case blockRe.MatchString(l), i+1 < len(lines) && setextText(l) && setextUnderline(lines[i+1]):
+			flush()
+			item = ""
+			out = append(out, l)
+		case listRe.MatchString(l):
+			flush()
+			m := listRe.FindStringSubmatch(l)
+			first, rest, cont = m[0], strings.Repeat(" ", len(m[0])), m[1]+"  "
+			item = m[1] + strings.Repeat(" ", len(m[2])+1)
+			para = append(para, l[len(m[0]):])
+		case len(para) > 0 && cont != "" && strings.HasPrefix(l, cont):
+			para = append(para, strings.TrimSpace(l))
+		case len(para) == 0 && item != "" && !strings.HasPrefix(l, item+"    "):
+			// a further paragraph of the list item
+			first, rest, cont = item, item, item
+			para = append(para, strings.TrimSpace(l))
+		case strings.HasPrefix(l, "    "), strings.HasPrefix(l, "\t"):
+			// an indented code block
+			flush()
+			out = append(out, l)
+		default:
+			if len(para) > 0 && cont != "" {
+				flush()
+			}
+			para = append(para, strings.TrimSpace(l))
+		}
+		if strings.HasSuffix(l, "  ") && len(para) > 0 {
+			hard = true
+			flush()
+		}
+	}
+	flush()
+	return strings.Join(out, "\n")
+}
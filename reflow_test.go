@@ -0,0 +1,62 @@
+package main
+
+import "testing"
+
+func TestReflow(t *testing.T) {
+	box := "┌────────────┐\n│ Scratchpad │\n└────────────┘\n"
+	for _, c := range []struct {
+		name  string
+		width int
+		in    string
+		want  string
+	}{
+		{"unwrap paragraph", 0,
+			"one\ntwo\nthree\n",
+			"one two three\n"},
+		{"wrap paragraph", 10,
+			"one two three four\n",
+			"one two\nthree four\n"},
+		{"list item", 0,
+			"- one\n  two\n- three\n",
+			"- one two\n- three\n"},
+		{"wrapped task", 13,
+			"- [ ] one two three\n",
+			"- [ ] one two\n      three\n"},
+		{"hard break", 0,
+			"one  \ntwo\n",
+			"one  \ntwo\n"},
+		{"fenced code", 0,
+			"```\na\nb\n```\n",
+			"```\na\nb\n```\n"},
+		{"headings", 0,
+			"# Title\n\n## Log\ntext\n",
+			"# Title\n\n## Log\ntext\n"},
+		{"box title unwrapped", 0, box, box},
+		{"box title wrapped", 80, box, box},
+		{"item paragraphs", 0,
+			"- title\n\n  first\n  para\n\n  second\n- next\n",
+			"- title\n\n  first para\n\n  second\n- next\n"},
+		{"item paragraph wrapped", 10,
+			"- title\n\n  one two three\n",
+			"- title\n\n  one two\n  three\n"},
+		{"numbered item paragraph", 0,
+			"1. title\n\n   more\n   text\n",
+			"1. title\n\n   more text\n"},
+		{"paragraph after list", 0,
+			"- item\n\nnot\nindented\n",
+			"- item\n\nnot indented\n"},
+		{"code in item", 0,
+			"- item\n\n      code\n      more\n",
+			"- item\n\n      code\n      more\n"},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			got := reflow(c.in, c.width)
+			if got != c.want {
+				t.Errorf("reflow(%q, %d) =\n%s\nwant\n%s", c.in, c.width, got, c.want)
+			}
+			if again := reflow(got, c.width); again != got {
+				t.Errorf("reflowing again changed it to\n%s", again)
+			}
+		})
+	}
+}
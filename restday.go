@@ -0,0 +1,21 @@
+package main
+
+import (
+	"strings"
+	"time"
+)
+
+// restDay reports whether t is a day off: a weekend with skip_weekends set,
+// or one of the holidays, given as YYYY-MM-DD or, for every year, MM-DD.
+func restDay(t time.Time) bool {
+	if confBool("skip_weekends", false) && (t.Weekday() == time.Saturday || t.Weekday() == time.Sunday) {
+		return true
+	}
+	for _, h := range strings.Split(conf("holidays", ""), ",") {
+		h = strings.TrimSpace(h)
+		if h != "" && (h == t.Format("2006-01-02") || h == t.Format("01-02")) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// runTail is how many lines of output are kept in the note.
+const runTail = 50
+
+// run executes a command, streaming its output, and logs the command, how
+// long it took, its exit status and the tail of its output to the pad.
+func run(args []string) {
+	if len(args) > 0 && args[0] == "--" {
+		args = args[1:]
+	}
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: scratch run -- <command> [args...]")
+		os.Exit(2)
+	}
+	var out bytes.Buffer
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = io.MultiWriter(os.Stdout, &out)
+	cmd.Stderr = io.MultiWriter(os.Stderr, &out)
+	start := time.Now()
+	err := runChild(cmd)
+	took := time.Since(start).Round(100 * time.Millisecond)
+	code := 0
+	var exit *exec.ExitError
+	if errors.As(err, &exit) {
+		code = exit.ExitCode()
+	} else if err != nil {
+		fmt.Fprintf(os.Stderr, "scratch: %v\n", err)
+		code = 127
+	}
+
+	e := logEntry(fmt.Sprintf("ran `%s`: exit %d after %v", shellJoin(args), code, took))
+	if lines := strings.Split(strings.TrimRight(out.String(), "\n"), "\n"); out.Len() > 0 {
+		summary := "output"
+		if len(lines) > runTail {
+			summary = fmt.Sprintf("output (last %d of %d lines)", runTail, len(lines))
+			lines = lines[len(lines)-runTail:]
+		}
+		fence := "```"
+		for strings.Contains(out.String(), fence) {
+			fence += "`"
+		}
+		e.Text += fmt.Sprintf("\n<details><summary>%s</summary>\n\n%s\n%s\n%s\n\n</details>",
+			summary, fence, strings.Join(lines, "\n"), fence)
+	}
+	appendInbox(e)
+	afterChild()
+	os.Exit(code)
+}
+
+// shellJoin joins args for display, quoting those that need it.
+func shellJoin(args []string) string {
+	quoted := make([]string, len(args))
+	for i, a := range args {
+		quoted[i] = a
+		if a == "" || strings.ContainsAny(a, " \t\n'\"$`\\|&;<>()*?") {
+			quoted[i] = "'" + strings.ReplaceAll(a, "'", `'\''`) + "'"
+		}
+	}
+	return strings.Join(quoted, " ")
+}
@@ -4,11 +4,14 @@ package main
 // Disposable command line notes
 
 import (
+	"flag"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
-	"os/user"
 	"path/filepath"
+	"strings"
+	"time"
 )
 
 func check(e error) {
@@ -17,53 +20,144 @@ func check(e error) {
 	}
 }
 
-func scratchpath() string {
-	usr, err := user.Current()
+func home() string {
+	h, err := os.UserHomeDir()
 	check(err)
+	return h
+}
+
+func padPath() string {
+	if p := profile(); p != "" {
+		return profilePad(p)
+	}
+	return filepath.Join(padDir(), "scratchpad.md")
+}
+
+// padDir is where pads are kept: the dir setting, or the home directory.
+func padDir() string {
+	return confPath("dir", home())
+}
+
+// swpPath is where vim keeps its swap file for the pad. vim names it after
+// the resolved file, so a symlinked pad has its swap file next to the target.
+func swpPath() string {
+	p := realPath(padPath())
+	return filepath.Join(filepath.Dir(p), "."+filepath.Base(p)+".swp")
+}
+
+// realPath resolves symlinks in p, leaving p as is if it doesn't exist yet.
+func realPath(p string) string {
+	r, err := filepath.EvalSymlinks(p)
+	if err != nil {
+		return p
+	}
+	return r
+}
+
+func fileMode() os.FileMode {
+	return confMode("mode", 0600)
+}
+
+func scratchpath(w io.Writer) string {
+	var err error
 	// Remove .swp while we're at it
 	// TODO: Pull into more explicit function
-	swp := filepath.Join(usr.HomeDir, ".scratchpad.md.swp")
-	f := filepath.Join(usr.HomeDir, "scratchpad.md")
+	swp := swpPath()
+	f := padPath()
 	if exists(swp) {
-		fmt.Println(".scratchpad.md.swp contents:\n")
-		cat(swp)
+		fmt.Fprintf(w, tr("%s contents:\n\n"), filepath.Base(swp))
+		cat(w, swp)
+		journal("delete", swp, hash(swp))
 		cmd := exec.Command("rm", swp)
 		cmd.Stdin = os.Stdin
-		cmd.Stdout = os.Stdout
+		cmd.Stdout = w
 		cmd.Stderr = os.Stderr
 		err = cmd.Run()
 		check(err)
 	}
-	fmt.Println("scratchpad.md contents:\n")
-	cat(f)
+	if exists(f) {
+		fmt.Fprintf(w, tr("%s contents:\n\n"), filepath.Base(f))
+		cat(w, f)
+	}
 	return f
 }
 
-func makePad(p string) {
-	f, err := os.Create(p)
+// makePadDir creates the directory the pad goes in, which the dir setting
+// may name before it exists.
+func makePadDir() {
+	d := filepath.Dir(padPath())
+	if err := os.MkdirAll(d, dirMode()); err != nil {
+		fmt.Fprintf(os.Stderr, tr("scratch: can't create %s for the scratchpad: %v\n"), d, err)
+		os.Exit(1)
+	}
+}
+
+func makePad(p, seed string) {
+	old := hash(p)
+	text := lineEndings(p, []byte(markProfile(seed)))
+	f, err := os.OpenFile(p, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, fileMode())
 	check(err)
 	defer f.Close()
-	_, err = f.WriteString("# Scratchpad\n\n\n")
+	_, err = f.Write(text)
 	f.Sync()
 	check(err)
+	journal("reset", p, old+" -> "+hash(p))
 }
 
-func cat(p string) {
+func cat(w io.Writer, p string) {
 	cmd := exec.Command("cat", p)
 	cmd.Stdin = os.Stdin
-	cmd.Stdout = os.Stdout
+	cmd.Stdout = w
 	cmd.Stderr = os.Stderr
 	err := cmd.Run()
 	check(err)
 }
 
-func openPad(p string) {
-	cmd := exec.Command("vim", p)
+// editor returns the editor command line for the named command: its
+// editor.<command> setting, else $VISUAL, then $EDITOR, then vim, followed by
+// any editor_args from the config.
+func editor(command string) []string {
+	e := []string{"vim"}
+	for _, v := range []string{conf("editor."+command, ""), os.Getenv("VISUAL"), os.Getenv("EDITOR")} {
+		if f := strings.Fields(v); len(f) > 0 {
+			e = f
+			break
+		}
+	}
+	return append(e, strings.Fields(conf("editor_args", ""))...)
+}
+
+// viewer returns the pager command line for the named command: its
+// viewer.<command> setting, else $PAGER, then less.
+func viewer(command string) []string {
+	for _, v := range []string{conf("viewer."+command, ""), os.Getenv("PAGER")} {
+		if f := strings.Fields(v); len(f) > 0 {
+			return f
+		}
+	}
+	return []string{"less"}
+}
+
+func openPad(command, p string) error {
+	e := editor(command)
+	if jumpLine > 0 {
+		e = append(e, fmt.Sprintf("+%d", jumpLine))
+	}
+	e = append(e, p)
+	if builtin {
+		capture(p)
+		return nil
+	}
+	if _, err := exec.LookPath(e[0]); err != nil {
+		fmt.Fprintf(os.Stderr, tr("scratch: %s not found; using the built-in editor\n"), e[0])
+		capture(p)
+		return nil
+	}
+	cmd := exec.Command(e[0], e[1:]...)
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
-	err := cmd.Run()
-	check(err)
+	return runChild(cmd)
 }
 
 func exists(file string) bool {
@@ -74,12 +168,219 @@ func exists(file string) bool {
 	}
 }
 
-func scratch() {
-	p := scratchpath()
-	makePad(p)
-	openPad(p)
+var noFormat, builtin, force bool
+
+func editFlags(name string, args []string) []string {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.BoolVar(&noFormat, "no-format", false, "don't run the formatter after editing")
+	fs.BoolVar(&builtin, "builtin", false, "use the built-in line editor")
+	fs.BoolVar(&force, "force", false, "start a fresh pad even on a weekend or holiday")
+	fs.Parse(args)
+	return fs.Args()
+}
+
+// edit opens the pad at p and tidies it up once the editor exits.
+func edit(command, p string) {
+	warnProfiles()
+	mergeInbox(p)
+	before, _ := readFile(p)
+	opened := time.Now()
+	err := openPad(command, p)
+	took := time.Since(opened)
+	journal("session", p, took.Round(time.Second).String())
+	if interrupted() {
+		mergeInbox(p)
+		afterChild()
+	}
+	if err != nil {
+		recoverPad(p, err)
+	}
+	after, _ := readFile(p)
+	mergeInbox(p)
+	if !noFormat {
+		format(p)
+		reflowPad(p)
+	}
+	warnSections(p)
+	warnSecrets(p)
+	sessionSummary(string(before), string(after), took)
+}
+
+func scratch(args []string) {
+	editFlags("scratch", args)
+	edit("scratch", rotate(os.Stdout))
+}
+
+// open starts a fresh pad like scratch. With -print it doesn't open the
+// editor but prints the pad's path, sending everything else to stderr, for
+// use like `nvim $(scratch open -print)`.
+func open(args []string) {
+	fs := flag.NewFlagSet("open", flag.ExitOnError)
+	fs.BoolVar(&noFormat, "no-format", false, "don't run the formatter after editing")
+	fs.BoolVar(&builtin, "builtin", false, "use the built-in line editor")
+	fs.BoolVar(&force, "force", false, "start a fresh pad even on a weekend or holiday")
+	printPath := fs.Bool("print", false, "print the pad's path instead of editing it")
+	fs.Parse(args)
+	if !*printPath {
+		edit("open", rotate(os.Stdout))
+		return
+	}
+	p, err := filepath.Abs(rotate(os.Stderr))
+	check(err)
+	fmt.Println(p)
+}
+
+// rotate prints the old pad and its swap file to w and replaces the pad with
+// a fresh one, returning its path. On a day off the existing pad is kept
+// unless -force is given.
+func rotate(w io.Writer) string {
+	checkQuota()
+	if p := padPath(); !force && exists(p) && restDay(time.Now()) {
+		fmt.Fprintln(os.Stderr, tr("scratch: keeping the current scratchpad on a day off; pass -force to start a fresh one"))
+		return p
+	}
+	makePadDir()
+	p := scratchpath(w)
+	plan := tomorrow(p)
+	if !confirmPlan(p, plan) {
+		plan = ""
+	}
+	makePad(p, seed(p))
+	carryPlan(p, plan)
+	return p
+}
+
+// last reopens the existing scratchpad without starting a fresh one.
+func last(args []string) {
+	editFlags("last", args)
+	checkQuota()
+	p := requirePad()
+	if confBool("interstitial", false) {
+		divide(p)
+	}
+	edit("last", p)
+}
+
+// divide starts a new timestamped section at the end of the pad, so a day's
+// note splits into sessions.
+func divide(p string) {
+	b, err := readFile(p)
+	check(err)
+	text := strings.TrimRight(string(b), "\n")
+	text += "\n\n" + sectionHeading(time.Now().Format("15:04")) + "\n\n"
+	writeAtomic(p, []byte(text))
+}
+
+// requirePad returns the pad's path, exiting if there isn't one yet.
+func requirePad() string {
+	p := padPath()
+	if !exists(p) {
+		fmt.Fprintln(os.Stderr, tr("scratch: no scratchpad yet; run scratch to start one"))
+		os.Exit(1)
+	}
+	return p
+}
+
+// noteArg resolves the note a command reads: the pad by default, else the
+// given file, where "-" means standard input.
+func noteArg(args []string) string {
+	if len(args) > 0 {
+		return args[0]
+	}
+	return requirePad()
+}
+
+// readNote reads the note at p. The pad is read with the inbox's pending
+// entries put in, without merging them.
+func readNote(p string) []byte {
+	if p == "-" {
+		b, err := io.ReadAll(os.Stdin)
+		check(err)
+		return b
+	}
+	b, err := readFile(p)
+	check(err)
+	if p == padPath() {
+		b = []byte(withPending(string(b)))
+	}
+	return b
+}
+
+// show displays a note, the pad by default, in a pager without editing it.
+// Entries still in the inbox are shown in the pad but not merged into it.
+func show(args []string) {
+	v := viewer("show")
+	var in io.Reader = os.Stdin
+	switch {
+	case len(args) > 0 && args[0] != "-":
+		v = append(v, args[0])
+	case len(args) == 0:
+		p := requirePad()
+		if len(pending()) == 0 {
+			v = append(v, p)
+			break
+		}
+		b, err := readFile(p)
+		check(err)
+		in = strings.NewReader(withPending(string(b)))
+	}
+	cmd := exec.Command(v[0], v[1:]...)
+	cmd.Stdin = in
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	err := runChild(cmd)
+	afterChild()
+	check(err)
+}
+
+// commands maps each subcommand to its handler. The default command, which
+// starts a fresh pad, has the empty name.
+var commands = map[string]func([]string){
+	"":             scratch,
+	"add":          add,
+	"count-open":   func([]string) { countOpen() },
+	"doctor":       func([]string) { doctor() },
+	"due":          due,
+	"edit":         last,
+	"edit-config":  func([]string) { editConfig() },
+	"env":          env,
+	"export":       export,
+	"history":      history,
+	"import":       importCmd,
+	"jump":         jump,
+	"last":         last,
+	"link":         link,
+	"nag":          func([]string) { nag() },
+	"open":         open,
+	"ref":          ref,
+	"run":          run,
+	"scan-secrets": scanSecrets,
+	"serve":        serve,
+	"show":         show,
+	"stats":        stats,
+	"summarize":    summarize,
+	"template":     templateCmd,
+	"timer":        timer,
+	"where":        where,
 }
 
 func main() {
-	scratch()
+	defer exitIfReadOnly()
+	handleSignals()
+	loadConfig()
+	cmd, args := "", os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		cmd, args = args[0], args[1:]
+	}
+	cmd, args = expand(cmd, args)
+	invoked = cmd
+	handler, ok := commands[cmd]
+	if !ok && isPlugin(cmd) {
+		plugin(cmd, args)
+	}
+	if !ok {
+		fmt.Fprintf(os.Stderr, tr("scratch: unknown command %q\n"), cmd)
+		os.Exit(2)
+	}
+	handler(args)
 }
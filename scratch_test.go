@@ -0,0 +1,308 @@
+package main
+
+// End-to-end tests: each runs the scratch binary in a temporary HOME, with
+// the test binary itself standing in for the editor.
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var scratchBin string
+
+func TestMain(m *testing.M) {
+	// Run as the stub editor: note the arguments, then type into the file.
+	if log := os.Getenv("STUB_EDITOR_LOG"); log != "" {
+		f, err := os.OpenFile(log, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+		check(err)
+		fmt.Fprintln(f, strings.Join(os.Args[1:], " "))
+		f.Close()
+		if text := os.Getenv("STUB_EDITOR_TYPES"); text != "" {
+			pad, err := os.OpenFile(os.Args[len(os.Args)-1], os.O_WRONLY|os.O_APPEND, 0600)
+			check(err)
+			fmt.Fprintln(pad, text)
+			pad.Close()
+		}
+		os.Exit(0)
+	}
+	dir, err := os.MkdirTemp("", "scratch-test-")
+	check(err)
+	scratchBin = filepath.Join(dir, "scratch")
+	build := exec.Command("go", "build", "-o", scratchBin, ".")
+	build.Stderr = os.Stderr
+	check(build.Run())
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// A sandbox is a throwaway HOME to run scratch in.
+type sandbox struct {
+	t    *testing.T
+	home string
+	env  []string
+}
+
+func newSandbox(t *testing.T) *sandbox {
+	home := t.TempDir()
+	self, err := os.Executable()
+	check(err)
+	env := []string{
+		"HOME=" + home,
+		"XDG_CONFIG_HOME=" + filepath.Join(home, ".config"),
+		"XDG_STATE_HOME=" + filepath.Join(home, ".local", "state"),
+		"PATH=" + os.Getenv("PATH"),
+		"LANG=C",
+		"PAGER=cat",
+		"EDITOR=" + self,
+		"STUB_EDITOR_LOG=" + filepath.Join(home, "editor.log"),
+	}
+	return &sandbox{t, home, env}
+}
+
+// run runs scratch with args and extra environment, returning its output
+// and exit status.
+func (s *sandbox) run(extra []string, args ...string) (stdout, stderr string, code int) {
+	s.t.Helper()
+	cmd := exec.Command(scratchBin, args...)
+	cmd.Env = append(append([]string{}, s.env...), extra...)
+	cmd.Dir = s.home
+	var out, errOut bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &out, &errOut
+	err := cmd.Run()
+	var exit *exec.ExitError
+	if errors.As(err, &exit) {
+		code = exit.ExitCode()
+	} else if err != nil {
+		s.t.Fatal(err)
+	}
+	return out.String(), errOut.String(), code
+}
+
+// ok runs scratch and fails the test unless it exits 0.
+func (s *sandbox) ok(args ...string) string {
+	s.t.Helper()
+	out, errOut, code := s.run(nil, args...)
+	if code != 0 {
+		s.t.Fatalf("scratch %s: exit %d\n%s", strings.Join(args, " "), code, errOut)
+	}
+	return out
+}
+
+func (s *sandbox) write(name, text string) {
+	p := filepath.Join(s.home, name)
+	check(os.MkdirAll(filepath.Dir(p), 0700))
+	check(os.WriteFile(p, []byte(text), 0600))
+}
+
+func (s *sandbox) read(name string) string {
+	b, err := os.ReadFile(filepath.Join(s.home, name))
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestStartsFreshPadAndOpensEditor(t *testing.T) {
+	s := newSandbox(t)
+	s.write("scratchpad.md", "# Old\n\nyesterday's note\n")
+	out := s.ok()
+	if !strings.Contains(out, "yesterday's note") {
+		t.Errorf("old pad not printed:\n%s", out)
+	}
+	pad := s.read("scratchpad.md")
+	if strings.Contains(pad, "yesterday's note") {
+		t.Errorf("pad not replaced:\n%s", pad)
+	}
+	if log := s.read("editor.log"); !strings.Contains(log, filepath.Join(s.home, "scratchpad.md")) {
+		t.Errorf("editor not opened on the pad: %q", log)
+	}
+}
+
+func TestCarriesTomorrowIntoPlan(t *testing.T) {
+	s := newSandbox(t)
+	s.write("scratchpad.md", "# Old\n\n## Tomorrow\n\n- [ ] ship it\n")
+	s.ok()
+	pad := s.read("scratchpad.md")
+	if !strings.Contains(pad, "## Plan\n\n- [ ] ship it\n") {
+		t.Errorf("plan not carried:\n%s", pad)
+	}
+}
+
+func TestEditorChangesAreKept(t *testing.T) {
+	s := newSandbox(t)
+	s.write("scratchpad.md", "# Today\n")
+	if _, errOut, code := s.run([]string{"STUB_EDITOR_TYPES=typed in editor"}, "last"); code != 0 {
+		t.Fatalf("exit %d\n%s", code, errOut)
+	}
+	if pad := s.read("scratchpad.md"); !strings.Contains(pad, "typed in editor") {
+		t.Errorf("edit lost:\n%s", pad)
+	}
+}
+
+func TestAddIsMergedWhenPadIsOpened(t *testing.T) {
+	s := newSandbox(t)
+	s.write("scratchpad.md", "# Today\n")
+	s.ok("add", "remember the milk")
+	if pad := s.read("scratchpad.md"); strings.Contains(pad, "milk") {
+		t.Errorf("add wrote the pad directly:\n%s", pad)
+	}
+	s.ok("last")
+	if pad := s.read("scratchpad.md"); !strings.Contains(pad, "remember the milk") {
+		t.Errorf("entry not merged:\n%s", pad)
+	}
+}
+
+// Commands that only read must leave the pad alone, as an editor may have it
+// open and would overwrite pending entries when it saves.
+func TestReadCommandsDontWritePad(t *testing.T) {
+	s := newSandbox(t)
+	s.write("scratchpad.md", "# Today\n")
+	s.write(".scratch/inbox.jsonl", `{"text":"- [ ] pending @due(2000-01-01)"}`+"\n")
+	for _, args := range [][]string{{"show"}, {"export"}, {"due"}, {"scan-secrets"}, {"count-open"}} {
+		out, _, _ := s.run(nil, args...)
+		if !strings.Contains(out, "pending") && args[0] != "count-open" && args[0] != "scan-secrets" {
+			t.Errorf("scratch %s doesn't show the pending entry:\n%s", args[0], out)
+		}
+		if pad := s.read("scratchpad.md"); pad != "# Today\n" {
+			t.Errorf("scratch %s wrote the pad:\n%s", args[0], pad)
+		}
+	}
+}
+
+func TestUnknownCommand(t *testing.T) {
+	s := newSandbox(t)
+	if _, _, code := s.run(nil, "no-such-command"); code != 2 {
+		t.Errorf("exit %d, want 2", code)
+	}
+}
+
+func TestEnvHidesTokens(t *testing.T) {
+	s := newSandbox(t)
+	s.write(".scratch/config", "token = hunter2\ntoken.sam = s3cret\n")
+	for _, args := range [][]string{{"env"}, {"env", "-json"}} {
+		out, _, _ := s.run([]string{"SCRATCH_TOKEN_BOB=b0b"}, args...)
+		for _, secret := range []string{"hunter2", "s3cret", "b0b"} {
+			if strings.Contains(out, secret) {
+				t.Errorf("scratch %s shows %q:\n%s", strings.Join(args, " "), secret, out)
+			}
+		}
+		if !strings.Contains(out, "(set)") {
+			t.Errorf("scratch %s doesn't say the tokens are set:\n%s", strings.Join(args, " "), out)
+		}
+	}
+}
+
+func TestPlugin(t *testing.T) {
+	s := newSandbox(t)
+	s.write("bin/scratch-hello", "#!/bin/sh\necho \"hello $1 $SCRATCH_TODAY\"\nexit ${2:-0}\n")
+	check(os.Chmod(filepath.Join(s.home, "bin", "scratch-hello"), 0755))
+	path := []string{"PATH=" + filepath.Join(s.home, "bin") + string(os.PathListSeparator) + os.Getenv("PATH")}
+	out, errOut, code := s.run(path, "hello", "world")
+	if code != 0 || errOut != "" {
+		t.Errorf("exit %d, stderr %q", code, errOut)
+	}
+	if want := "hello world " + filepath.Join(s.home, "scratchpad.md") + "\n"; out != want {
+		t.Errorf("got %q, want %q", out, want)
+	}
+	if _, _, code := s.run(path, "hello", "world", "3"); code != 3 {
+		t.Errorf("exit %d, want the plugin's 3", code)
+	}
+}
+
+// A line cut short in the inbox, say by a full disk, mustn't stop the pad
+// from opening or be lost.
+func TestDamagedInboxLine(t *testing.T) {
+	s := newSandbox(t)
+	s.write("scratchpad.md", "# Today\n")
+	s.write(".scratch/inbox.jsonl.merging", `{"text":"- 10:00 whole"}`+"\n"+`{"text":"- 10:01 cut sh`)
+	if out := s.ok("show"); !strings.Contains(out, "whole") || !strings.Contains(out, "cut sh") {
+		t.Errorf("show doesn't have both entries:\n%s", out)
+	}
+	_, errOut, code := s.run(nil, "last")
+	if code != 0 || !strings.Contains(errOut, "damaged") {
+		t.Fatalf("exit %d, stderr %q", code, errOut)
+	}
+	pad := s.read("scratchpad.md")
+	if !strings.Contains(pad, "- 10:00 whole\n") || !strings.Contains(pad, "cut sh") {
+		t.Errorf("entries not merged:\n%s", pad)
+	}
+	s.ok("last")
+}
+
+// Without a terminal to ask on, stale plans are carried rather than lost
+// with the old pad.
+func TestStalePlanCarriedWithoutTerminal(t *testing.T) {
+	s := newSandbox(t)
+	s.write("scratchpad.md", "# Old\n\n## Tomorrow\n\n- [ ] ship it\n")
+	old := time.Now().Add(-72 * time.Hour)
+	check(os.Chtimes(filepath.Join(s.home, "scratchpad.md"), old, old))
+	s.ok()
+	if pad := s.read("scratchpad.md"); !strings.Contains(pad, "- [ ] ship it") {
+		t.Errorf("plan lost:\n%s", pad)
+	}
+}
+
+// The dir setting may name a directory that doesn't exist yet.
+func TestDirIsCreated(t *testing.T) {
+	s := newSandbox(t)
+	s.write(".scratch/config", "dir = ~/notes/today\n")
+	s.ok()
+	if pad := s.read("notes/today/scratchpad.md"); !strings.Contains(pad, "#") {
+		t.Errorf("no pad in the new dir:\n%s", pad)
+	}
+	s.write("file", "")
+	s.write(".scratch/config", "dir = ~/file/notes\n")
+	_, errOut, code := s.run(nil)
+	if code != 1 || !strings.Contains(errOut, "can't create") || strings.Contains(errOut, "panic") {
+		t.Errorf("exit %d, stderr %q", code, errOut)
+	}
+}
+
+// A transform seeds the new pad from the old one, which mustn't pile up
+// profile marks or carried plans from one day to the next.
+func TestTransformDoesntDuplicate(t *testing.T) {
+	s := newSandbox(t)
+	s.write(".scratch/config", "transform = cat\nprofile = work\npad.work = ~/scratchpad.md\n")
+	s.write("scratchpad.md", "# Old\n\n## Tomorrow\n\n- [ ] ship it\n")
+	s.ok()
+	s.ok()
+	pad := s.read("scratchpad.md")
+	if n := strings.Count(pad, "- [ ] ship it"); n != 1 {
+		t.Errorf("plan appears %d times:\n%s", n, pad)
+	}
+	if n := strings.Count(pad, "scratch profile:"); n != 1 {
+		t.Errorf("profile marked %d times:\n%s", n, pad)
+	}
+	if strings.Contains(pad, "## Tomorrow") {
+		t.Errorf("old plans kept:\n%s", pad)
+	}
+}
+
+// Rewriting the pad, as merging does, respects the umask like creating it.
+func TestRewriteRespectsUmask(t *testing.T) {
+	s := newSandbox(t)
+	s.write(".scratch/config", "mode = 0644\n")
+	s.write("scratchpad.md", "# Today\n")
+	s.ok("add", "merged")
+	cmd := exec.Command("sh", "-c", "umask 077 && exec \"$0\" last", scratchBin)
+	cmd.Env = s.env
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("%v\n%s", err, out)
+	}
+	fi, err := os.Stat(filepath.Join(s.home, "scratchpad.md"))
+	check(err)
+	if !strings.Contains(s.read("scratchpad.md"), "merged") {
+		t.Fatal("entry not merged")
+	}
+	if mode := fi.Mode().Perm(); mode != 0600 {
+		t.Errorf("pad is %#o, want 0600", mode)
+	}
+}
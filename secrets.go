@@ -0,0 +1,152 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// secretPatterns are checked for in notes. Where a pattern has a capture
+// group, only the group is the secret.
+var secretPatterns = map[string]string{
+	"aws-access-key": `\b(AKIA[0-9A-Z]{16})\b`,
+	"github-token":   `\b(gh[pousr]_[A-Za-z0-9]{36,})\b`,
+	"slack-token":    `\b(xox[abprs]-[A-Za-z0-9-]{10,})`,
+	"stripe-key":     `\b([sr]k_live_[0-9A-Za-z]{24,})\b`,
+	"google-api-key": `\b(AIza[0-9A-Za-z_-]{35})\b`,
+	"api-key":        `\b(sk-[A-Za-z0-9_-]{20,})`,
+	"private-key":    `-----BEGIN [A-Z ]*PRIVATE KEY-----`,
+	"password":       `(?i)\b(?:password|passwd|pwd|secret|api[_-]?key|token)\s*[:=]\s*(\S{6,})`,
+}
+
+var tokenRe = regexp.MustCompile(`[A-Za-z0-9+/=_-]{20,}`)
+
+// masked is what a secret's tail is replaced with.
+const masked = "********"
+
+type finding struct {
+	line   int
+	kind   string
+	secret string
+}
+
+// scanSecrets reports likely secrets in a note, the pad by default. With
+// -mask it masks them in place, or prints the masked note for stdin.
+func scanSecrets(args []string) {
+	fs := flag.NewFlagSet("scan-secrets", flag.ExitOnError)
+	mask := fs.Bool("mask", false, "mask the secrets that were found")
+	fs.Parse(args)
+	p := noteArg(fs.Args())
+	if *mask && p == padPath() {
+		// masking rewrites the pad, so take in the pending entries for good
+		mergeInbox(p)
+	}
+	text := string(readNote(p))
+	found := findSecrets(text)
+	name := p
+	if p == "-" {
+		name = "<stdin>"
+	}
+	for _, f := range found {
+		fmt.Fprintf(os.Stderr, tr("%s:%d: possible %s %s\n"), name, f.line, f.kind, maskSecret(f.secret))
+	}
+	if !*mask {
+		if len(found) > 0 {
+			os.Exit(1)
+		}
+		return
+	}
+	out := text
+	for _, f := range found {
+		out = strings.ReplaceAll(out, f.secret, maskSecret(f.secret))
+	}
+	if p == "-" {
+		fmt.Print(out)
+		return
+	}
+	if out != text {
+		writeAtomic(p, []byte(out))
+		journal("mask", p, sum([]byte(text))+" -> "+sum([]byte(out)))
+	}
+}
+
+// warnSecrets is the post-edit check enabled by scan_secrets.
+func warnSecrets(p string) {
+	if !confBool("scan_secrets", false) {
+		return
+	}
+	b, err := readFile(p)
+	check(err)
+	if found := findSecrets(string(b)); len(found) > 0 {
+		fmt.Fprintf(os.Stderr, tr("scratch: %d possible secrets in %s; run scratch scan-secrets -mask\n"),
+			len(found), p)
+	}
+}
+
+func findSecrets(text string) []finding {
+	patterns := map[string]*regexp.Regexp{}
+	for kind, re := range secretPatterns {
+		patterns[kind] = regexp.MustCompile(re)
+	}
+	for k, re := range config {
+		if kind, ok := strings.CutPrefix(k, "secret_pattern."); ok {
+			patterns[kind] = regexp.MustCompile(re)
+		}
+	}
+	kinds := make([]string, 0, len(patterns))
+	for k := range patterns {
+		kinds = append(kinds, k)
+	}
+	sort.Strings(kinds)
+	threshold, err := strconv.ParseFloat(conf("secret_entropy", "4.5"), 64)
+	check(err)
+
+	var found []finding
+	for i, line := range strings.Split(text, "\n") {
+		seen := map[string]bool{}
+		for _, kind := range kinds {
+			for _, m := range patterns[kind].FindAllStringSubmatch(line, -1) {
+				s := m[len(m)-1]
+				if !seen[s] && !strings.HasSuffix(s, masked) {
+					seen[s] = true
+					found = append(found, finding{i + 1, kind, s})
+				}
+			}
+		}
+		for _, tok := range tokenRe.FindAllString(line, -1) {
+			if !seen[tok] && entropy(tok) >= threshold {
+				seen[tok] = true
+				found = append(found, finding{i + 1, "high-entropy string", tok})
+			}
+		}
+	}
+	return found
+}
+
+// entropy is the Shannon entropy of s in bits per character.
+func entropy(s string) float64 {
+	counts := map[rune]int{}
+	for _, r := range s {
+		counts[r]++
+	}
+	var h float64
+	n := float64(len([]rune(s)))
+	for _, c := range counts {
+		p := float64(c) / n
+		h -= p * math.Log2(p)
+	}
+	return h
+}
+
+func maskSecret(s string) string {
+	keep := 4
+	if len(s) < 12 {
+		keep = 0
+	}
+	return s[:keep] + masked
+}
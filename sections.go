@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// schema returns the required sections from the `sections` setting.
+func schema() []string {
+	var names []string
+	for _, s := range strings.Split(conf("sections", ""), ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			names = append(names, s)
+		}
+	}
+	return names
+}
+
+// headings returns the names of the note's sections.
+func headings(text string) []string {
+	var hs []string
+	for _, h := range sectionHeadings(strings.Split(text, "\n")) {
+		hs = append(hs, h.name)
+	}
+	return hs
+}
+
+// A heading is a level-two heading found in a note.
+type heading struct {
+	name string
+	line int // where the heading starts
+	body int // the first line after it
+}
+
+// sectionHeadings finds the level-two headings in lines outside code fences,
+// whether written ATX style (`## Name`) or setext style (`Name` over `---`).
+func sectionHeadings(lines []string) []heading {
+	var hs []heading
+	fenced := false
+	for i, line := range lines {
+		if strings.HasPrefix(line, "```") {
+			fenced = !fenced
+			continue
+		}
+		if fenced {
+			continue
+		}
+		if strings.HasPrefix(line, "## ") {
+			hs = append(hs, heading{strings.TrimSpace(line[3:]), i, i + 1})
+		} else if i+1 < len(lines) && setextText(line) && setextUnderline(lines[i+1]) {
+			hs = append(hs, heading{strings.TrimSpace(line), i, i + 2})
+		}
+	}
+	return hs
+}
+
+func setextText(line string) bool {
+	t := strings.TrimSpace(line)
+	return t != "" && !strings.HasPrefix(t, "#") && !strings.HasPrefix(t, "- ") &&
+		!strings.HasPrefix(t, "* ") && !strings.HasPrefix(t, "+ ") && !strings.HasPrefix(t, ">")
+}
+
+func setextUnderline(line string) bool {
+	t := strings.TrimSpace(line)
+	return len(t) >= 3 && strings.Trim(t, "-") == ""
+}
+
+// section finds the named section in lines, returning the index of its first
+// line after the heading and the index where the section ends.
+func section(lines []string, name string) (body, end int, ok bool) {
+	hs := sectionHeadings(lines)
+	for i, h := range hs {
+		if h.name != name {
+			continue
+		}
+		end = len(lines)
+		if i+1 < len(hs) {
+			end = hs[i+1].line
+		}
+		return h.body, end, true
+	}
+	return 0, 0, false
+}
+
+// validate checks the pad at p against the sections schema.
+func validate(p string) []string {
+	want := schema()
+	if len(want) == 0 || !exists(p) {
+		return nil
+	}
+	b, err := readFile(p)
+	check(err)
+	have := map[string]bool{}
+	var problems []string
+	for _, h := range headings(string(b)) {
+		have[h] = true
+		if !contains(want, h) && !isDivider(h) {
+			problems = append(problems, fmt.Sprintf(tr("unknown section %q"), h))
+		}
+	}
+	for _, w := range want {
+		if !have[w] {
+			problems = append(problems, fmt.Sprintf(tr("missing section %q"), w))
+		}
+	}
+	return problems
+}
+
+// isDivider reports whether h is a session divider added by interstitial
+// mode.
+func isDivider(h string) bool {
+	_, err := time.Parse("15:04", h)
+	return err == nil && confBool("interstitial", false)
+}
+
+func warnSections(p string) {
+	for _, problem := range validate(p) {
+		fmt.Fprintf(os.Stderr, "scratch: %s\n", problem)
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
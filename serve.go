@@ -0,0 +1,224 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"html/template"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var sharePage = template.Must(template.New("share").Parse(`<!doctype html>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width">
+<title>scratchpad</title>
+<pre style="white-space: pre-wrap; max-width: 80ch; margin: 2em auto">{{.}}</pre>
+`))
+
+func serve(args []string) {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := fs.String("addr", ":0", "address to listen on")
+	share := fs.Bool("share", false, "share the scratchpad read-only at a secret URL")
+	appendOn := fs.Bool("append", false, "accept entries at POST /append")
+	ics := fs.Bool("ics", false, "serve a calendar of due tasks and events at a secret URL")
+	ui := fs.Bool("ui", false, "serve a web page for reading, searching and adding to the scratchpad")
+	ttl := fs.Duration("ttl", 0, "how long to serve before exiting (default 1h with -share or -ui, forever otherwise)")
+	fs.Parse(args)
+	if !*share && !*appendOn && !*ui && !*ics {
+		fmt.Fprintln(os.Stderr, "usage: scratch serve [-share] [-append] [-ui] [-ics] [-ttl 1h] [-addr :0]")
+		os.Exit(2)
+	}
+	if *ttl == 0 && (*share || *ui) {
+		*ttl = time.Hour
+	}
+
+	mux := http.NewServeMux()
+	l, err := net.Listen("tcp", *addr)
+	check(err)
+	cert, key := confPath("tls_cert", ""), confPath("tls_key", "")
+	base := "http://" + hostPort(l)
+	if cert != "" {
+		base = "https://" + hostPort(l)
+	}
+	if *share {
+		path := "/" + token()
+		mux.HandleFunc(path, sharePad)
+		fmt.Printf(tr("Sharing scratchpad at %s%s\n"), base, path)
+	}
+	if *ics {
+		path := "/" + token() + ".ics"
+		mux.HandleFunc(path, serveCalendar)
+		fmt.Printf(tr("Calendar at %s%s\n"), base, path)
+	}
+	perMinute, err := strconv.Atoi(conf("rate_limit", "30"))
+	check(err)
+	if *ui {
+		path := "/" + token() + "/"
+		mux.Handle(path, uiHandler(path, &limiter{per: perMinute}))
+		fmt.Printf(tr("Web page at %s%s\n"), base, path)
+	}
+	if *appendOn {
+		tokens := appendTokens()
+		if len(tokens) == 0 {
+			fmt.Fprintln(os.Stderr, tr("scratch: set token in the config to accept appends"))
+			os.Exit(2)
+		}
+		mux.Handle("/append", appendHandler(tokens, &limiter{per: perMinute}))
+		fmt.Printf(tr("Accepting entries at %s/append\n"), base)
+	}
+
+	// the server faces the network, so don't let slow clients hold it open
+	srv := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       30 * time.Second,
+		IdleTimeout:       2 * time.Minute,
+	}
+	if *ttl > 0 {
+		fmt.Printf(tr("Serving for %v\n"), *ttl)
+		time.AfterFunc(*ttl, func() { srv.Shutdown(context.Background()) })
+	}
+	if cert != "" {
+		err = srv.ServeTLS(l, cert, key)
+	} else {
+		err = srv.Serve(l)
+	}
+	if err != http.ErrServerClosed {
+		check(err)
+	}
+}
+
+func sharePad(w http.ResponseWriter, r *http.Request) {
+	b, err := readFile(padPath())
+	if err != nil {
+		http.Error(w, "no scratchpad", http.StatusNotFound)
+		return
+	}
+	sharePage.Execute(w, string(b))
+}
+
+// appendTokens maps each bearer token accepted by /append to whose it is:
+// "" for the owner's `token`, or the name from a `token.<name>` setting.
+func appendTokens() map[string]string {
+	tokens := map[string]string{}
+	if t := conf("token", ""); t != "" {
+		tokens[t] = ""
+	}
+	for k := range config {
+		if name, ok := strings.CutPrefix(k, "token."); ok {
+			if t := conf(k, ""); t != "" {
+				tokens[t] = name
+			}
+		}
+	}
+	return tokens
+}
+
+// appendHandler queues the posted text (a "text" form field or the raw body)
+// as an entry, for clients presenting one of the bearer tokens. Entries from
+// anyone but the owner are attributed, like `[sam 14:20] text`, and kept to
+// one line.
+func appendHandler(tokens map[string]string, lim *limiter) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "POST only", http.StatusMethodNotAllowed)
+			return
+		}
+		host, _, _ := net.SplitHostPort(r.RemoteAddr)
+		if !lim.allow(host) {
+			http.Error(w, "slow down", http.StatusTooManyRequests)
+			return
+		}
+		got, bearer := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		user, ok := "", false
+		for t, name := range tokens {
+			if subtle.ConstantTimeCompare([]byte(got), []byte(t)) == 1 {
+				user, ok = name, true
+			}
+		}
+		if !ok || !bearer {
+			http.Error(w, "bad token", http.StatusUnauthorized)
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, 64<<10)
+		text := r.FormValue("text")
+		if text == "" {
+			b, err := io.ReadAll(r.Body)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			text = string(b)
+		}
+		if text = strings.TrimSpace(text); text == "" {
+			http.Error(w, "nothing to append", http.StatusBadRequest)
+			return
+		}
+		e := logEntry(text)
+		if user != "" {
+			// one line only, so they can't add sections or unattributed lines
+			text = strings.Join(strings.Fields(text), " ")
+			e.Text = fmt.Sprintf("- [%s %s] %s", user, time.Now().Format("15:04"), text)
+		}
+		appendInbox(e)
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// A limiter allows each client a number of requests per minute.
+type limiter struct {
+	per int
+
+	mu     sync.Mutex
+	window time.Time
+	seen   map[string]int
+}
+
+func (l *limiter) allow(client string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if now := time.Now(); now.Sub(l.window) > time.Minute {
+		l.window, l.seen = now, map[string]int{}
+	}
+	l.seen[client]++
+	return l.seen[client] <= l.per
+}
+
+func token() string {
+	b := make([]byte, 16)
+	_, err := rand.Read(b)
+	check(err)
+	return hex.EncodeToString(b)
+}
+
+func hostPort(l net.Listener) string {
+	a := l.Addr().(*net.TCPAddr)
+	host := a.IP.String()
+	if a.IP.IsUnspecified() {
+		host = lanIP()
+	}
+	return net.JoinHostPort(host, fmt.Sprint(a.Port))
+}
+
+// lanIP returns the first non-loopback IPv4 address, so the printed URL
+// works from other machines on the network.
+func lanIP() string {
+	addrs, err := net.InterfaceAddrs()
+	check(err)
+	for _, a := range addrs {
+		if n, ok := a.(*net.IPNet); ok && !n.IP.IsLoopback() && n.IP.To4() != nil {
+			return n.IP.String()
+		}
+	}
+	return "localhost"
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestAppendHandler(t *testing.T) {
+	tokens := map[string]string{"owner-token": "", "sam-token": "sam"}
+	for _, c := range []struct {
+		name, auth, text string
+		status           int
+		want             string
+	}{
+		{"owner", "Bearer owner-token", "hello", http.StatusNoContent, "hello"},
+		{"no prefix", "owner-token", "hello", http.StatusUnauthorized, ""},
+		{"wrong token", "Bearer nope", "hello", http.StatusUnauthorized, ""},
+		{"paired user", "Bearer sam-token", "hi there", http.StatusNoContent, "] hi there"},
+		{"paired user newlines", "Bearer sam-token", "one\n## Injected\n- sneaky", http.StatusNoContent, "] one ## Injected - sneaky"},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			t.Setenv("HOME", t.TempDir())
+			h := appendHandler(tokens, &limiter{per: 100})
+			req := httptest.NewRequest("POST", "/append", strings.NewReader(url.Values{"text": {c.text}}.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			req.Header.Set("Authorization", c.auth)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+			if w.Code != c.status {
+				t.Fatalf("status %d, want %d", w.Code, c.status)
+			}
+			entries := pending()
+			if c.want == "" {
+				if len(entries) > 0 {
+					t.Errorf("appended %q", entries)
+				}
+				return
+			}
+			if len(entries) != 1 || !strings.Contains(entries[0].Text, c.want) || strings.Contains(entries[0].Text, "\n") {
+				t.Errorf("got entries %q, want one line containing %q", entries, c.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// invoked is the command scratch was run as, after alias expansion.
+var invoked string
+
+// A sidecar record describes where an entry came from. Records are kept out
+// of the note, one JSON line per entry in a file per day.
+type sidecar struct {
+	Time    time.Time `json:"time"`
+	Command string    `json:"command"`
+	Args    []string  `json:"args,omitempty"`
+	Dir     string    `json:"cwd,omitempty"`
+	Host    string    `json:"host,omitempty"`
+	Profile string    `json:"profile,omitempty"`
+	Section string    `json:"section,omitempty"`
+	Text    string    `json:"text"`
+}
+
+func sidecarPath(t time.Time) string {
+	d := filepath.Join(stateDir(), "sidecar")
+	check(os.MkdirAll(d, dirMode()))
+	return filepath.Join(d, t.Format("2006-01-02")+".jsonl")
+}
+
+// recordSidecar notes the context of an entry when the sidecar setting is on.
+func recordSidecar(e entry) {
+	if !confBool("sidecar", false) {
+		return
+	}
+	now := time.Now()
+	dir, _ := os.Getwd()
+	host, _ := os.Hostname()
+	b, err := json.Marshal(sidecar{now, invoked, os.Args[1:], dir, host, profile(), e.Section, e.Text})
+	check(err)
+	f, err := os.OpenFile(sidecarPath(now), os.O_WRONLY|os.O_CREATE|os.O_APPEND, fileMode())
+	check(err)
+	defer f.Close()
+	_, err = f.Write(append(b, '\n'))
+	check(err)
+}
@@ -0,0 +1,108 @@
+package main
+
+// On SIGINT, SIGTERM or SIGHUP scratch removes its temporary files before
+// exiting. While the editor or a `scratch run` command is in the foreground
+// it gets the terminal's Ctrl-C itself, so scratch ignores SIGINT and passes
+// other signals on, then finishes up (merging the inbox) once it exits.
+// Helpers handle Ctrl-C themselves; see helper.go.
+
+import (
+	"os"
+	"os/exec"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+var (
+	sigMu   sync.Mutex
+	temps   []string
+	child   *os.Process
+	caught  os.Signal
+	helpers int
+)
+
+func handleSignals() {
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	go func() {
+		for s := range c {
+			sigMu.Lock()
+			if child != nil {
+				if s != syscall.SIGINT {
+					caught = s
+					child.Signal(s)
+				}
+				sigMu.Unlock()
+				continue
+			}
+			if helpers > 0 && s == syscall.SIGINT {
+				sigMu.Unlock()
+				continue
+			}
+			sigMu.Unlock()
+			exitOn(s)
+		}
+	}()
+}
+
+// exitOn cleans up and exits as if killed by s.
+func exitOn(s os.Signal) {
+	sigMu.Lock()
+	for _, t := range temps {
+		os.RemoveAll(t)
+	}
+	sigMu.Unlock()
+	os.Exit(128 + int(s.(syscall.Signal)))
+}
+
+// removeOnExit has the temporary file or directory t removed if scratch is
+// interrupted.
+func removeOnExit(t string) {
+	sigMu.Lock()
+	defer sigMu.Unlock()
+	temps = append(temps, t)
+}
+
+// runChild runs cmd in the foreground, passing signals other than SIGINT on
+// to it.
+func runChild(cmd *exec.Cmd) error {
+	sigMu.Lock()
+	err := cmd.Start()
+	if err == nil {
+		child = cmd.Process
+	}
+	sigMu.Unlock()
+	if err != nil {
+		return err
+	}
+	err = cmd.Wait()
+	sigMu.Lock()
+	child = nil
+	sigMu.Unlock()
+	return err
+}
+
+// interrupted reports whether a signal was passed on to the child.
+func interrupted() bool {
+	sigMu.Lock()
+	defer sigMu.Unlock()
+	return caught != nil
+}
+
+// afterChild exits if a signal was passed on to the child that just ended,
+// resetting the terminal in case the child left it in raw mode.
+func afterChild() {
+	sigMu.Lock()
+	s := caught
+	sigMu.Unlock()
+	if s == nil {
+		return
+	}
+	if fi, err := os.Stdin.Stat(); err == nil && fi.Mode()&os.ModeCharDevice != 0 {
+		stty := exec.Command("stty", "sane")
+		stty.Stdin = os.Stdin
+		stty.Run()
+	}
+	exitOn(s)
+}
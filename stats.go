@@ -0,0 +1,212 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A size is the number of bytes used by a file or section.
+type size struct {
+	name  string
+	bytes int64
+}
+
+// usage returns the bytes used by each of scratch's files.
+func usage() []size {
+	return []size{
+		{"pad", fileSize(padPath())},
+		{"inbox", fileSize(inboxPath())},
+		{"journal", fileSize(journalPath())},
+		{"templates", dirSize(templateDir())},
+	}
+}
+
+func totalUsage() int64 {
+	var total int64
+	for _, s := range usage() {
+		total += s.bytes
+	}
+	return total
+}
+
+func fileSize(p string) int64 {
+	fi, err := os.Stat(p)
+	if err != nil {
+		return 0
+	}
+	return fi.Size()
+}
+
+func dirSize(d string) int64 {
+	var n int64
+	filepath.WalkDir(d, func(p string, e fs.DirEntry, err error) error {
+		if err == nil && !e.IsDir() {
+			n += fileSize(p)
+		}
+		return nil
+	})
+	return n
+}
+
+// quota returns the configured size limit in bytes, or 0 for none.
+func quota() int64 {
+	q := strings.ToUpper(strings.TrimSpace(conf("quota", "")))
+	if q == "" {
+		return 0
+	}
+	mult := int64(1)
+	for i, unit := range []string{"K", "M", "G"} {
+		if strings.HasSuffix(q, unit) {
+			q = strings.TrimSuffix(q, unit)
+			mult = 1 << (10 * (i + 1))
+		}
+	}
+	n, err := strconv.ParseInt(q, 10, 64)
+	check(err)
+	return n * mult
+}
+
+// checkQuota warns when scratch's files outgrow the quota, or exits if
+// quota_action is "refuse".
+func checkQuota() {
+	q := quota()
+	used := totalUsage()
+	if q == 0 || used <= q {
+		return
+	}
+	if conf("quota_action", "warn") == "refuse" {
+		fmt.Fprintf(os.Stderr, tr("scratch: refusing to write: scratch files use %s, over the %s quota; see scratch stats\n"),
+			humanSize(used), humanSize(q))
+		os.Exit(1)
+	}
+	fmt.Fprintf(os.Stderr, tr("scratch: scratch files use %s, over the %s quota; see scratch stats\n"),
+		humanSize(used), humanSize(q))
+}
+
+// stats prints how much space scratch uses and which sections of the pad
+// take up the most of it.
+func stats(args []string) {
+	fs := flag.NewFlagSet("stats", flag.ExitOnError)
+	editTime := fs.Bool("time", false, "report time spent in the editor over the last week")
+	truncateFlag(fs)
+	fs.Parse(args)
+	if *editTime {
+		sessionStats()
+		return
+	}
+	for _, s := range usage() {
+		fmt.Printf("%-10s %7s\n", s.name, humanSize(s.bytes))
+	}
+	total := fmt.Sprintf("%-10s %7s", "total", humanSize(totalUsage()))
+	if q := quota(); q > 0 {
+		total += fmt.Sprintf("  (quota %s)", humanSize(q))
+	}
+	fmt.Println(total)
+
+	b, err := readFile(padPath())
+	if err != nil || len(b) == 0 {
+		return
+	}
+	fmt.Println()
+	for _, s := range sectionSizes(string(b)) {
+		fmt.Println(fit(fmt.Sprintf("%7s  %s", humanSize(s.bytes), s.name)))
+	}
+}
+
+// sectionSizes returns the size of each section of text, largest first.
+func sectionSizes(text string) []size {
+	lines := strings.Split(text, "\n")
+	hs := sectionHeadings(lines)
+	bytes := func(from, to int) int64 {
+		var n int64
+		for _, l := range lines[from:to] {
+			n += int64(len(l)) + 1
+		}
+		return n
+	}
+	end := len(lines)
+	if len(hs) > 0 {
+		end = hs[0].line
+	}
+	sizes := []size{{"(top)", bytes(0, end)}}
+	for i, h := range hs {
+		end := len(lines)
+		if i+1 < len(hs) {
+			end = hs[i+1].line
+		}
+		sizes = append(sizes, size{h.name, bytes(h.line, end)})
+	}
+	sort.SliceStable(sizes, func(i, j int) bool { return sizes[i].bytes > sizes[j].bytes })
+	return sizes
+}
+
+func humanSize(n int64) string {
+	switch {
+	case n >= 1<<30:
+		return fmt.Sprintf("%.1fG", float64(n)/(1<<30))
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1fM", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1fK", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%dB", n)
+}
+
+// sessionStats prints the time spent in the editor on each of the last seven
+// days. Sessions longer than session_cap, say with the laptop asleep, count
+// as session_cap.
+func sessionStats() {
+	limit, err := time.ParseDuration(conf("session_cap", "4h"))
+	check(err)
+	y, m, d := time.Now().Date()
+	since := time.Date(y, m, d, 0, 0, 0, 0, time.Local).AddDate(0, 0, -6)
+	days := map[string]time.Duration{}
+	capped := map[string]int{}
+	f, err := os.Open(journalPath())
+	if os.IsNotExist(err) {
+		return
+	}
+	check(err)
+	defer f.Close()
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		fields := strings.SplitN(s.Text(), "\t", 4)
+		if len(fields) != 4 || fields[1] != "session" {
+			continue
+		}
+		at, err := time.Parse(time.RFC3339, fields[0])
+		if err != nil || at.Before(since) {
+			continue
+		}
+		dur, err := time.ParseDuration(fields[3])
+		if err != nil {
+			continue
+		}
+		day := at.Local().Format("2006-01-02")
+		if dur > limit {
+			dur = limit
+			capped[day]++
+		}
+		days[day] += dur
+	}
+	check(s.Err())
+	var total time.Duration
+	for i := 0; i < 7; i++ {
+		day := since.AddDate(0, 0, i).Format("2006-01-02")
+		line := fmt.Sprintf("%s %9v", day, days[day].Round(time.Minute))
+		if capped[day] > 0 {
+			line += fmt.Sprintf(tr("  (%d capped at %v)"), capped[day], limit)
+		}
+		fmt.Println(fit(line))
+		total += days[day]
+	}
+	fmt.Printf("%-10s %9v\n", "total", total.Round(time.Minute))
+}
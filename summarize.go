@@ -0,0 +1,69 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const defaultPrompt = "Summarize these notes in a few short bullet points.\n\n"
+
+// summarize pipes a note through the summarize_cmd setting, e.g. `llm`, and
+// files the result under the pad's Summary section. scratch itself never
+// talks to a model; that is left to the command.
+func summarize(args []string) {
+	fs := flag.NewFlagSet("summarize", flag.ExitOnError)
+	toStdout := fs.Bool("print", false, "print the summary instead of adding it to the pad")
+	fs.Parse(args)
+	if fs.NArg() > 1 {
+		fmt.Fprintln(os.Stderr, "usage: scratch summarize [-print] [file|-]")
+		os.Exit(2)
+	}
+	command := conf("summarize_cmd", "")
+	if command == "" {
+		fmt.Fprintln(os.Stderr, tr("scratch: set summarize_cmd in the config, e.g. summarize_cmd = llm"))
+		os.Exit(1)
+	}
+	p := noteArg(fs.Args())
+	note := readNote(p)
+	prompt := strings.NewReader(conf("summarize_prompt", defaultPrompt) + string(note))
+	out, err := helper("summarize", prompt, os.Stderr, "sh", "-c", command)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, tr("scratch: summarize_cmd failed: %v\n"), err)
+		os.Exit(1)
+	}
+	summary := strings.TrimSpace(string(out))
+	if *toStdout || p != padPath() {
+		fmt.Println(summary)
+		return
+	}
+	mergeInbox(p)
+	note, err = readFile(p)
+	check(err)
+	text := replaceSection(string(note), "Summary", summary)
+	writeAtomic(p, []byte(text))
+	journal("summarize", p, sum(note)+" -> "+sum([]byte(text)))
+}
+
+// replaceSection swaps the body of the named section for body, adding the
+// section if text doesn't have it.
+func replaceSection(text, name, body string) string {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	first, end, ok := section(lines, name)
+	if !ok {
+		return insert(text, entry{Section: name, Text: body})
+	}
+	var b strings.Builder
+	for _, l := range lines[:first] {
+		b.WriteString(l + "\n")
+	}
+	b.WriteString("\n" + body + "\n")
+	if end < len(lines) {
+		b.WriteString("\n")
+		for _, l := range lines[end:] {
+			b.WriteString(l + "\n")
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// sessionSummary tells the user what an editor session changed: words added
+// and removed, tasks created and completed, and how long it took. With bell
+// set it rings the terminal bell too.
+func sessionSummary(before, after string, took time.Duration) {
+	if !confBool("exit_summary", true) {
+		return
+	}
+	added, removed := wordDiff(before, after)
+	open, done := taskStates(before)
+	created, completed := 0, 0
+	nowOpen, nowDone := taskStates(after)
+	for t := range nowOpen {
+		if !open[t] && !done[t] {
+			created++
+		}
+	}
+	for t := range nowDone {
+		if open[t] {
+			completed++
+		}
+	}
+	bell := ""
+	if confBool("bell", false) {
+		bell = "\a"
+	}
+	fmt.Fprintf(os.Stderr, tr("scratch: +%d/-%d words, %d new tasks, %d done, %v%s\n"),
+		added, removed, created, completed, took.Round(time.Second), bell)
+}
+
+// wordDiff counts the words in after that weren't in before, and the other
+// way round, ignoring where they are and markdown such as list markers.
+func wordDiff(before, after string) (added, removed int) {
+	count := map[string]int{}
+	for _, w := range words(before) {
+		count[w]++
+	}
+	for _, w := range words(after) {
+		count[w]--
+	}
+	for _, n := range count {
+		if n > 0 {
+			removed += n
+		} else {
+			added -= n
+		}
+	}
+	return added, removed
+}
+
+func words(text string) []string {
+	var ws []string
+	for _, w := range strings.Fields(text) {
+		if strings.IndexFunc(w, unicode.IsLetter) >= 0 || strings.IndexFunc(w, unicode.IsDigit) >= 0 {
+			if w != "[x]" && w != "[X]" {
+				ws = append(ws, w)
+			}
+		}
+	}
+	return ws
+}
+
+// taskStates returns the text of the open and done tasks in a note.
+func taskStates(text string) (open, done map[string]bool) {
+	open, done = map[string]bool{}, map[string]bool{}
+	for _, l := range strings.Split(text, "\n") {
+		if m := taskRe.FindStringSubmatch(l); m != nil {
+			if m[1] == " " {
+				open[m[2]] = true
+			} else {
+				done[m[2]] = true
+			}
+		}
+	}
+	return open, done
+}
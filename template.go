@@ -0,0 +1,137 @@
+package main
+
+// New pads are rendered from templates in ~/.scratch/templates. The most
+// specific file for the day wins: monday.md ... sunday.md, then weekday.md
+// or weekend.md, then base.md. Templates use text/template syntax, so an
+// override can pull in a more general one with {{template "base" .}}.
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+)
+
+func templateDir() string {
+	return filepath.Join(stateDir(), "templates")
+}
+
+// builtinTemplate is used as "base" when there is no base.md.
+func builtinTemplate() string {
+	t := titleHeading("Scratchpad") + "\n\n\n"
+	for _, s := range schema() {
+		t += sectionHeading(s) + "\n\n\n"
+	}
+	return t
+}
+
+func headingStyle() string {
+	style := conf("heading_style", "plain")
+	switch style {
+	case "plain", "setext", "box", "emoji":
+		return style
+	}
+	check(fmt.Errorf("unknown heading_style %q; use plain, setext, box or emoji", style))
+	return ""
+}
+
+// titleHeading renders the note's title in the configured heading style.
+func titleHeading(title string) string {
+	switch headingStyle() {
+	case "setext":
+		return title + "\n" + strings.Repeat("=", textWidth(title))
+	case "box":
+		bar := strings.Repeat("─", textWidth(title)+2)
+		return "┌" + bar + "┐\n│ " + title + " │\n└" + bar + "┘"
+	case "emoji":
+		return "# 📝 " + title
+	}
+	return "# " + title
+}
+
+// sectionHeading renders a section heading. Only setext has its own
+// level-two form; the other styles decorate the title alone.
+func sectionHeading(name string) string {
+	if headingStyle() == "setext" {
+		return name + "\n" + strings.Repeat("-", max(3, textWidth(name)))
+	}
+	return "## " + name
+}
+
+func padTemplate() string {
+	return renderTemplate(time.Now())
+}
+
+// templateNames lists the templates that can apply to day, most general
+// first.
+func templateNames(day time.Time) []string {
+	kind := "weekday"
+	if day.Weekday() == time.Saturday || day.Weekday() == time.Sunday {
+		kind = "weekend"
+	}
+	return []string{"base", kind, strings.ToLower(day.Weekday().String())}
+}
+
+// templateFile returns the template file used for day, or "" for the
+// built-in template.
+func templateFile(day time.Time) string {
+	use := ""
+	for _, name := range templateNames(day) {
+		if p := filepath.Join(templateDir(), name+".md"); exists(p) {
+			use = p
+		}
+	}
+	return use
+}
+
+// renderTemplate renders the template for the given day.
+func renderTemplate(day time.Time) string {
+	t := template.New("base")
+	_, err := t.Parse(builtinTemplate())
+	check(err)
+	use := "base"
+	for _, name := range templateNames(day) {
+		b, err := readFile(filepath.Join(templateDir(), name+".md"))
+		if os.IsNotExist(err) {
+			continue
+		}
+		check(err)
+		_, err = t.New(name).Parse(string(b))
+		check(err)
+		use = name
+	}
+	var sb strings.Builder
+	check(t.ExecuteTemplate(&sb, use, struct{ Date time.Time }{day}))
+	return sb.String()
+}
+
+// templateCmd handles `scratch template preview [day]`.
+func templateCmd(args []string) {
+	if len(args) == 0 || args[0] != "preview" || len(args) > 2 {
+		fmt.Fprintln(os.Stderr, "usage: scratch template preview [monday|...|sunday]")
+		os.Exit(2)
+	}
+	day := time.Now()
+	if len(args) == 2 {
+		d, ok := nextWeekday(day, args[1])
+		if !ok {
+			fmt.Fprintf(os.Stderr, tr("scratch: unknown day %q\n"), args[1])
+			os.Exit(2)
+		}
+		day = d
+	}
+	fmt.Print(renderTemplate(day))
+}
+
+// nextWeekday returns the first day on or after from named name.
+func nextWeekday(from time.Time, name string) (time.Time, bool) {
+	for i := 0; i < 7; i++ {
+		d := from.AddDate(0, 0, i)
+		if strings.EqualFold(d.Weekday().String(), name) {
+			return d, true
+		}
+	}
+	return from, false
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// The running timer is kept in ~/.scratch/timer as "<RFC3339 start> <label>".
+// Finished timers are logged to the pad and the journal; reports are built
+// from the journal since old pads are discarded.
+
+func timerPath() string {
+	return filepath.Join(stateDir(), "timer")
+}
+
+func timer(args []string) {
+	if len(args) == 0 {
+		timerUsage()
+	}
+	switch args[0] {
+	case "start":
+		label := strings.Join(args[1:], " ")
+		if label == "" {
+			timerUsage()
+		}
+		timerStop()
+		check(os.WriteFile(timerPath(), []byte(time.Now().Format(time.RFC3339)+" "+label), fileMode()))
+		appendInbox(logEntry("timer start: " + label))
+	case "stop":
+		if !timerStop() {
+			fmt.Fprintln(os.Stderr, tr("scratch: no timer running"))
+			os.Exit(1)
+		}
+	case "report":
+		timerReport(args[1:])
+	default:
+		timerUsage()
+	}
+}
+
+func timerUsage() {
+	fmt.Fprintln(os.Stderr, "usage: scratch timer start <label> | stop | report [-week] [-no-truncate]")
+	os.Exit(2)
+}
+
+// timerStop stops the running timer, if any, and logs how long it ran.
+func timerStop() bool {
+	b, err := os.ReadFile(timerPath())
+	if os.IsNotExist(err) {
+		return false
+	}
+	check(err)
+	stamp, label, _ := strings.Cut(string(b), " ")
+	start, err := time.Parse(time.RFC3339, stamp)
+	check(err)
+	took := time.Since(start).Round(time.Minute)
+	appendInbox(logEntry(fmt.Sprintf("timer stop: %s (%v)", label, took)))
+	journal("timer", padPath(), fmt.Sprintf("%v %s", took, label))
+	check(os.Remove(timerPath()))
+	return true
+}
+
+// timerReport totals the time per label for today, or the last seven days.
+func timerReport(args []string) {
+	fs := flag.NewFlagSet("timer report", flag.ExitOnError)
+	week := fs.Bool("week", false, "report the last seven days instead of today")
+	truncateFlag(fs)
+	fs.Parse(args)
+	y, m, d := time.Now().Date()
+	since := time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+	if *week {
+		since = since.AddDate(0, 0, -6)
+	}
+
+	totals := map[string]time.Duration{}
+	f, err := os.Open(journalPath())
+	if os.IsNotExist(err) {
+		return
+	}
+	check(err)
+	defer f.Close()
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		fields := strings.SplitN(s.Text(), "\t", 4)
+		if len(fields) != 4 || fields[1] != "timer" {
+			continue
+		}
+		at, err := time.Parse(time.RFC3339, fields[0])
+		if err != nil || at.Before(since) {
+			continue
+		}
+		took, label, _ := strings.Cut(fields[3], " ")
+		dur, err := time.ParseDuration(took)
+		if err != nil {
+			continue
+		}
+		totals[label] += dur
+	}
+	check(s.Err())
+
+	labels := make([]string, 0, len(totals))
+	for l := range totals {
+		labels = append(labels, l)
+	}
+	sort.Slice(labels, func(i, j int) bool { return totals[labels[i]] > totals[labels[j]] })
+	for _, l := range labels {
+		fmt.Println(fit(fmt.Sprintf("%8v  %s", totals[l], l)))
+	}
+}
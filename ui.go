@@ -0,0 +1,147 @@
+package main
+
+import (
+	_ "embed"
+	"html"
+	"html/template"
+	"net"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+//go:embed ui.html
+var uiSource string
+
+var uiPage = template.Must(template.New("ui").Parse(uiSource))
+
+// uiHandler serves a small web page under path for reading the pad,
+// searching it and adding entries from a phone. The secret path is the
+// only credential, as with -share.
+func uiHandler(path string, lim *limiter) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case path:
+			b, err := readFile(padPath())
+			if err != nil {
+				http.Error(w, "no scratchpad", http.StatusNotFound)
+				return
+			}
+			q := strings.TrimSpace(r.FormValue("q"))
+			var matches []string
+			if q != "" {
+				for _, l := range strings.Split(string(b), "\n") {
+					if strings.Contains(strings.ToLower(l), strings.ToLower(q)) {
+						matches = append(matches, l)
+					}
+				}
+			}
+			uiPage.Execute(w, struct {
+				Path, Query string
+				Matches     []string
+				Pending     []entry
+				Note        template.HTML
+			}{path, q, matches, pending(), render(string(b))})
+		case path + "append":
+			host, _, _ := net.SplitHostPort(r.RemoteAddr)
+			if r.Method != http.MethodPost {
+				http.Error(w, "POST only", http.StatusMethodNotAllowed)
+				return
+			}
+			if !lim.allow(host) {
+				http.Error(w, "slow down", http.StatusTooManyRequests)
+				return
+			}
+			r.Body = http.MaxBytesReader(w, r.Body, 64<<10)
+			if text := strings.TrimSpace(r.FormValue("text")); text != "" {
+				appendInbox(logEntry(text))
+			}
+			http.Redirect(w, r, path, http.StatusSeeOther)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+var (
+	linkRe = regexp.MustCompile(`\[([^\]]+)\]\((https?://[^)\s]+)\)`)
+	codeRe = regexp.MustCompile("`([^`]+)`")
+)
+
+// render turns the markdown scratch writes into HTML: headings, lists and
+// checkboxes, code blocks, links and inline code. Anything else is shown as
+// paragraphs.
+func render(text string) template.HTML {
+	var b strings.Builder
+	lines := strings.Split(text, "\n")
+	hs := map[int]bool{}
+	for _, h := range sectionHeadings(lines) {
+		hs[h.line] = true
+	}
+	inList, fenced := false, false
+	endList := func() {
+		if inList {
+			b.WriteString("</ul>\n")
+			inList = false
+		}
+	}
+	for i := 0; i < len(lines); i++ {
+		l := lines[i]
+		switch {
+		case strings.HasPrefix(l, "```"):
+			endList()
+			if fenced {
+				b.WriteString("</pre>\n")
+			} else {
+				b.WriteString("<pre>")
+			}
+			fenced = !fenced
+		case fenced:
+			b.WriteString(html.EscapeString(l) + "\n")
+		case hs[i]:
+			endList()
+			name := strings.TrimSpace(strings.TrimPrefix(l, "## "))
+			b.WriteString("<h2>" + inline(name) + "</h2>\n")
+			if !strings.HasPrefix(l, "## ") {
+				i++ // skip the setext underline
+			}
+		case strings.HasPrefix(l, "# "):
+			endList()
+			b.WriteString("<h1>" + inline(l[2:]) + "</h1>\n")
+		case strings.HasPrefix(l, "### "):
+			endList()
+			b.WriteString("<h3>" + inline(l[4:]) + "</h3>\n")
+		case listRe.MatchString(l):
+			if !inList {
+				b.WriteString("<ul>\n")
+				inList = true
+			}
+			if m := taskRe.FindStringSubmatch(l); m != nil {
+				if m[1] == " " {
+					b.WriteString("<li>☐ " + inline(m[2]) + "</li>\n")
+				} else {
+					b.WriteString(`<li class="done">☑ ` + inline(m[2]) + "</li>\n")
+				}
+			} else {
+				b.WriteString("<li>" + inline(l[len(listRe.FindString(l)):]) + "</li>\n")
+			}
+		case strings.TrimSpace(l) == "":
+			endList()
+		default:
+			endList()
+			b.WriteString("<p>" + inline(l) + "</p>\n")
+		}
+	}
+	endList()
+	if fenced {
+		b.WriteString("</pre>\n")
+	}
+	return template.HTML(b.String())
+}
+
+// inline escapes a line of text and renders its links and code spans.
+func inline(s string) string {
+	s = html.EscapeString(s)
+	s = codeRe.ReplaceAllString(s, "<code>$1</code>")
+	return linkRe.ReplaceAllString(s, `<a href="$2">$1</a>`)
+}
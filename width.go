@@ -0,0 +1,85 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var noTruncate bool
+
+// truncateFlag registers -no-truncate on a command's flags.
+func truncateFlag(fs *flag.FlagSet) {
+	fs.BoolVar(&noTruncate, "no-truncate", false, "don't cut lines to the terminal cols")
+}
+
+var cols = -1
+
+// termWidth returns the width of the terminal, or 0 when stdout isn't one.
+func termWidth() int {
+	if cols >= 0 {
+		return cols
+	}
+	cols = 0
+	if fi, err := os.Stdout.Stat(); err != nil || fi.Mode()&os.ModeCharDevice == 0 {
+		return cols
+	}
+	if n, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && n > 0 {
+		cols = n
+		return cols
+	}
+	cmd := exec.Command("stty", "size")
+	cmd.Stdin = os.Stdin
+	out, err := cmd.Output()
+	if err != nil {
+		return cols
+	}
+	if f := strings.Fields(string(out)); len(f) == 2 {
+		cols, _ = strconv.Atoi(f[1])
+	}
+	return cols
+}
+
+// fit cuts s to the terminal cols, marking the cut with an ellipsis.
+func fit(s string) string {
+	w := termWidth()
+	if noTruncate || w <= 0 || textWidth(s) <= w {
+		return s
+	}
+	n := 0
+	for i, r := range s {
+		if n+runeWidth(r) > w-1 {
+			return s[:i] + "…"
+		}
+		n += runeWidth(r)
+	}
+	return s
+}
+
+// textWidth returns how many terminal columns s takes up.
+func textWidth(s string) int {
+	n := 0
+	for _, r := range s {
+		n += runeWidth(r)
+	}
+	return n
+}
+
+// runeWidth returns the columns r takes up: none for combining marks and
+// joiners, two for wide East Asian characters and emoji, else one.
+func runeWidth(r rune) int {
+	switch {
+	case unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf), r >= 0xFE00 && r <= 0xFE0F:
+		return 0
+	case r >= 0x1100 && r <= 0x115F, r >= 0x2E80 && r <= 0xA4CF && r != 0x303F,
+		r >= 0xAC00 && r <= 0xD7A3, r >= 0xF900 && r <= 0xFAFF, r >= 0xFE30 && r <= 0xFE4F,
+		r >= 0xFF00 && r <= 0xFF60, r >= 0xFFE0 && r <= 0xFFE6,
+		r >= 0x1F300 && r <= 0x1F64F, r >= 0x1F900 && r <= 0x1F9FF,
+		r >= 0x20000 && r <= 0x3FFFD:
+		return 2
+	}
+	return 1
+}
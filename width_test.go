@@ -0,0 +1,64 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTextWidth(t *testing.T) {
+	for _, c := range []struct {
+		s    string
+		want int
+	}{
+		{"plain", 5},
+		{"café", 4},
+		{"café", 4}, // combining accent
+		{"日本語", 6},
+		{"한국어", 6},
+		{"📝 notes", 8},
+		{"❤️", 1}, // variation selector
+		{"ｆｕｌｌ", 8},
+	} {
+		if got := textWidth(c.s); got != c.want {
+			t.Errorf("textWidth(%q) = %d, want %d", c.s, got, c.want)
+		}
+	}
+}
+
+func TestFit(t *testing.T) {
+	defer func(old int) { cols = old }(cols)
+	cols = 10
+	for _, c := range []struct{ s, want string }{
+		{"short", "short"},
+		{"exactly 10", "exactly 10"},
+		{"a bit too long", "a bit too…"},
+		{"日本語のテキスト", "日本語の…"},
+		{"ab日本語のテキスト", "ab日本語…"},
+		{"📝📝📝📝📝📝", "📝📝📝📝…"},
+	} {
+		got := fit(c.s)
+		if got != c.want {
+			t.Errorf("fit(%q) = %q, want %q", c.s, got, c.want)
+		}
+		if textWidth(got) > cols {
+			t.Errorf("fit(%q) is %d columns wide", c.s, textWidth(got))
+		}
+	}
+}
+
+func TestTitleHeadingWidth(t *testing.T) {
+	for _, title := range []string{"Scratchpad", "メモ帳", "Notizen 📝", "Café"} {
+		t.Setenv("SCRATCH_HEADING_STYLE", "box")
+		lines := strings.Split(titleHeading(title), "\n")
+		for _, l := range lines[1:] {
+			if textWidth(l) != textWidth(lines[0]) {
+				t.Errorf("box around %q is ragged:\n%s", title, strings.Join(lines, "\n"))
+			}
+		}
+		t.Setenv("SCRATCH_HEADING_STYLE", "setext")
+		lines = strings.Split(titleHeading(title), "\n")
+		if textWidth(lines[1]) != textWidth(title) {
+			t.Errorf("setext underline for %q is %d wide, want %d", title, textWidth(lines[1]), textWidth(title))
+		}
+	}
+}